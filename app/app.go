@@ -4,26 +4,45 @@ import (
 	"HelAIx/pkg/config"
 	"HelAIx/pkg/gemini"
 	"HelAIx/pkg/helix"
+	"HelAIx/pkg/usage"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// Version is the HelAIx version recorded in the metadata of generated presets.
+// Release builds set it from the tag: wails build -ldflags "-X main.Version=v1.0.0"
+var Version = "dev"
+
 // App struct
 type App struct {
 	ctx    context.Context
 	config *config.Manager
+	ledger *usage.Ledger
+	cache  *gemini.ResponseCache // Agent response cache, shared by every request
+
+	requestsMu sync.Mutex
+	requests   map[string]*context.CancelFunc // In-flight agent requests by ID
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
+	cfg := config.NewManager()
 	return &App{
-		config: config.NewManager(),
+		config:   cfg,
+		ledger:   usage.NewLedger(filepath.Join(cfg.Dir(), "usage.jsonl"), filepath.Join(cfg.Dir(), "usage.json")),
+		cache:    gemini.NewResponseCache(filepath.Join(cfg.Dir(), "cache"), 0, 0),
+		requests: make(map[string]*context.CancelFunc),
 	}
 }
 
@@ -31,6 +50,18 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+
+	// Load the user overlay catalog (models learned from .hlx files)
+	if entries, err := helix.LoadOverlay(a.overlayPath()); err != nil {
+		log.Printf("Error loading overlay catalog: %v", err)
+	} else {
+		helix.ApplyOverlay(entries)
+	}
+}
+
+// overlayPath returns the location of the user overlay catalog
+func (a *App) overlayPath() string {
+	return filepath.Join(a.config.Dir(), "catalog_overlay.json")
 }
 
 // GxGetConfig returns the current configuration
@@ -47,36 +78,375 @@ func (a *App) GxSaveConfig(cfg config.AppConfig) string {
 	return ""
 }
 
+// RequestOptions identifies an agent request
+type RequestOptions struct {
+	RequestID   string `json:"request_id"`   // Used by GxCancelRequest and progress events
+	ChatID      string `json:"chat_id"`      // Token usage is recorded against this chat
+	BypassCache bool   `json:"bypass_cache"` // Force a fresh response even if an identical one is cached
+}
+
 // GxChatSoundEngineer calls the Sound Engineer Agent with history
-func (a *App) GxChatSoundEngineer(history []gemini.ChatMessage) (*gemini.RigDescription, error) {
+func (a *App) GxChatSoundEngineer(opts RequestOptions, history []gemini.ChatMessage) (*gemini.RigDescription, error) {
 	cfg := a.config.Get()
 	if cfg.ApiKey == "" {
 		return nil, fmt.Errorf("API Key is missing")
 	}
 
-	client, err := gemini.NewClient(a.ctx, cfg.ApiKey, cfg.Model)
+	ctx, done := a.beginRequest(opts.RequestID)
+	defer done()
+
+	client, err := gemini.NewClient(ctx, cfg.ApiKey, cfg.Model)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AI client: %v", err)
 	}
 	defer client.Close()
-
-	return client.ChatSoundEngineer(a.ctx, history, cfg.VariaxHardwareModel)
+	client.Fallbacks = cfg.FallbackModels
+	client.OnProgress = a.progressEmitter(opts.RequestID)
+	client.OnUsage = a.usageRecorder(opts.ChatID)
+	client.Cache = a.responseCache(cfg)
+	client.OnCacheError = logCacheError
+	client.BypassCache = opts.BypassCache
+
+	rig, err := client.ChatSoundEngineer(ctx, history, cfg.VariaxHardwareModel)
+	if ctx.Err() == context.Canceled {
+		return nil, errRequestCancelled
+	}
+	return rig, err
 }
 
 // GxChatPresetEngineer calls the Preset Engineer Agent with history and baseline rig
-func (a *App) GxChatPresetEngineer(rig gemini.RigDescription, presetName string, history []gemini.ChatMessage) (*helix.Preset, error) {
+func (a *App) GxChatPresetEngineer(opts RequestOptions, rig gemini.RigDescription, presetName string, history []gemini.ChatMessage) (*gemini.PresetResult, error) {
 	cfg := a.config.Get()
 	if cfg.ApiKey == "" {
 		return nil, fmt.Errorf("API Key is missing")
 	}
 
-	client, err := gemini.NewClient(a.ctx, cfg.ApiKey, cfg.Model)
+	ctx, done := a.beginRequest(opts.RequestID)
+	defer done()
+
+	client, err := gemini.NewClient(ctx, cfg.ApiKey, cfg.Model)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AI client: %v", err)
 	}
 	defer client.Close()
+	client.Fallbacks = cfg.FallbackModels
+	client.OnProgress = a.progressEmitter(opts.RequestID)
+	client.OnUsage = a.usageRecorder(opts.ChatID)
+	client.Cache = a.responseCache(cfg)
+	client.OnCacheError = logCacheError
+	client.BypassCache = opts.BypassCache
+
+	result, err := client.ChatPresetEngineer(ctx, &rig, presetName, history, cfg.HardwareTarget, cfg.DefaultExpPedal, cfg.VariaxEnabled, cfg.VariaxHardwareModel, cfg.FirmwareVersion)
+	if ctx.Err() == context.Canceled {
+		// Drop any partially built preset
+		return nil, errRequestCancelled
+	}
+	if err == nil {
+		stampMetadata(result.Preset, cfg)
+	}
+	if err == nil && cfg.GainStaging {
+		if db, dbErr := helix.CatalogFor(cfg.FirmwareVersion); dbErr == nil {
+			db.NormalizeLevels(result.Preset, a.gainStaging(cfg))
+		}
+	}
+	return result, err
+}
+
+// stampMetadata completes the preset metadata with what only the app knows: the author and the HelAIx version
+func stampMetadata(preset *helix.Preset, cfg config.AppConfig) {
+	meta, _ := preset.Metadata()
+	meta.Author = cfg.PresetAuthor
+	meta.Version = Version
+	preset.SetMetadata(meta)
+}
+
+// GxGetUsage returns the token usage and estimated spend per chat, day and model
+func (a *App) GxGetUsage() usage.Summary {
+	s, err := a.ledger.Summary(a.config.Get().ModelPrices)
+	if err != nil {
+		log.Printf("Error reading usage ledger: %v", err)
+	}
+	return s
+}
+
+// usageRecorder stores the token usage of a chat's provider calls in the ledger
+func (a *App) usageRecorder(chatID string) gemini.UsageFunc {
+	return func(u gemini.Usage) {
+		err := a.ledger.Add(usage.Record{
+			ChatID:         chatID,
+			Stage:          u.Stage,
+			Model:          u.Model,
+			PromptTokens:   u.PromptTokens,
+			ResponseTokens: u.ResponseTokens,
+		})
+		if err != nil {
+			log.Printf("Error saving usage ledger: %v", err)
+		}
+	}
+}
+
+// responseCache returns the agent response cache with the limits of the settings, or nil if disabled.
+// Every request shares the same cache, so concurrent writes and evictions are serialized.
+func (a *App) responseCache(cfg config.AppConfig) *gemini.ResponseCache {
+	if !cfg.CacheEnabled {
+		return nil
+	}
+	a.cache.Configure(time.Duration(cfg.CacheTTLHours)*time.Hour, int64(cfg.CacheMaxMB)<<20)
+	return a.cache
+}
+
+// logCacheError reports a response that could not be cached
+func logCacheError(err error) {
+	log.Printf("Error writing response cache: %v", err)
+}
+
+// GxClearResponseCache deletes every cached agent response
+func (a *App) GxClearResponseCache() error {
+	return a.cache.Clear()
+}
+
+// GxCancelRequest stops an in-flight agent request. It returns false if the request is unknown or already finished.
+func (a *App) GxCancelRequest(requestID string) bool {
+	a.requestsMu.Lock()
+	cancel, ok := a.requests[requestID]
+	a.requestsMu.Unlock()
+	if ok {
+		(*cancel)()
+	}
+	return ok
+}
+
+// providerError is the frontend representation of a gemini.Error
+type providerError struct {
+	Kind       gemini.ErrorKind `json:"kind"`
+	Message    string           `json:"message"`
+	Model      string           `json:"model,omitempty"`
+	RetryAfter int              `json:"retry_after,omitempty"` // Seconds
+}
+
+// formatError sends provider errors to the frontend as typed objects (mapped to localized messages)
+// and every other error as a plain string
+func formatError(err error) any {
+	var gerr *gemini.Error
+	if errors.As(err, &gerr) {
+		return providerError{
+			Kind:       gerr.Kind,
+			Message:    gerr.Message,
+			Model:      gerr.Model,
+			RetryAfter: int(math.Ceil(gerr.RetryAfter.Seconds())),
+		}
+	}
+	return err.Error()
+}
+
+// errRequestCancelled is returned by agent bindings stopped with GxCancelRequest
+var errRequestCancelled = errors.New("request cancelled")
+
+// beginRequest registers a cancellable context for an agent request. done must be called when the request ends.
+func (a *App) beginRequest(requestID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(a.ctx)
+	if requestID == "" {
+		return ctx, cancel
+	}
+
+	a.requestsMu.Lock()
+	if previous, ok := a.requests[requestID]; ok {
+		(*previous)() // Same ID reused: the old request is superseded
+	}
+	entry := &cancel
+	a.requests[requestID] = entry
+	a.requestsMu.Unlock()
+
+	return ctx, func() {
+		a.requestsMu.Lock()
+		if a.requests[requestID] == entry {
+			delete(a.requests, requestID)
+		}
+		a.requestsMu.Unlock()
+		cancel()
+	}
+}
+
+// progressEventName is the Wails event name carrying agent progress payloads
+const progressEventName = "gx:progress"
+
+// requestProgress is a gemini.ProgressEvent tagged with the request it belongs to
+type requestProgress struct {
+	RequestID string `json:"request_id"`
+	gemini.ProgressEvent
+}
+
+// progressEmitter forwards the agent progress of a request to the frontend
+func (a *App) progressEmitter(requestID string) gemini.ProgressFunc {
+	return func(ev gemini.ProgressEvent) {
+		runtime.EventsEmit(a.ctx, progressEventName, requestProgress{RequestID: requestID, ProgressEvent: ev})
+	}
+}
+
+// GxSearchCatalog searches the Helix model catalog (typo tolerant, with category and DSP facets)
+func (a *App) GxSearchCatalog(query helix.SearchQuery) ([]helix.SearchResult, error) {
+	db, err := helix.CatalogFor(a.config.Get().FirmwareVersion)
+	if err != nil {
+		return nil, err
+	}
+	return db.Search(query), nil
+}
+
+// GxGetModelParams returns the parameter metadata (type, range, unit) of a catalog model
+// The catalog of the configured firmware is used, like the Preset Engineer does.
+func (a *App) GxGetModelParams(modelID string) ([]helix.ParamInfo, error) {
+	db, err := helix.CatalogFor(a.config.Get().FirmwareVersion)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := db.FindByID(modelID)
+	if !ok {
+		return []helix.ParamInfo{}, nil
+	}
+	return entry.Params(), nil
+}
+
+// GxFormatParams renders block param values as displayed on the hardware (e.g. "375 ms", "-3.0 dB")
+func (a *App) GxFormatParams(modelID string, params map[string]interface{}) (map[string]string, error) {
+	db, err := helix.CatalogFor(a.config.Get().FirmwareVersion)
+	if err != nil {
+		return nil, err
+	}
+	return db.FormatParams(modelID, params), nil
+}
+
+// GxGetSupportedFirmware returns the Helix firmware versions with an embedded catalog
+func (a *App) GxGetSupportedFirmware() []string {
+	return helix.SupportedFirmware()
+}
+
+// GxCheckCompatibility lists the blocks of a preset that don't exist on the configured firmware
+func (a *App) GxCheckCompatibility(preset helix.Preset) ([]helix.CompatibilityIssue, error) {
+	db, err := helix.CatalogFor(a.config.Get().FirmwareVersion)
+	if err != nil {
+		return nil, err
+	}
+	return db.CheckCompatibility(&preset), nil
+}
 
-	return client.ChatPresetEngineer(a.ctx, &rig, presetName, history, cfg.HardwareTarget, cfg.DefaultExpPedal, cfg.VariaxEnabled, cfg.VariaxHardwareModel)
+// editPreset applies a manual edit with the configured hardware and firmware, then re-runs the DSP and
+// snapshot consistency checks
+func (a *App) editPreset(preset helix.Preset, edit func(*helix.Editor) error) (*helix.EditResult, error) {
+	cfg := a.config.Get()
+	db, err := helix.CatalogFor(cfg.FirmwareVersion)
+	if err != nil {
+		return nil, err
+	}
+	editor := helix.NewEditor(&preset, db, helix.IsDualDSP(cfg.HardwareTarget))
+	if err := edit(editor); err != nil {
+		return nil, err
+	}
+	return editor.Result(cfg.HardwareTarget), nil
+}
+
+// GxValidatePreset lints a preset for the configured hardware and firmware
+func (a *App) GxValidatePreset(preset helix.Preset) ([]helix.Diagnostic, error) {
+	cfg := a.config.Get()
+	db, err := helix.CatalogFor(cfg.FirmwareVersion)
+	if err != nil {
+		return nil, err
+	}
+	return db.Validate(&preset, cfg.HardwareTarget), nil
+}
+
+// GxNormalizeLevels balances the output level of every snapshot of a preset
+func (a *App) GxNormalizeLevels(preset helix.Preset) (*helix.LevelResult, error) {
+	cfg := a.config.Get()
+	db, err := helix.CatalogFor(cfg.FirmwareVersion)
+	if err != nil {
+		return nil, err
+	}
+	return db.NormalizeLevels(&preset, a.gainStaging(cfg)), nil
+}
+
+// GxMatchSetlist matches the volume of presets (files or folders of .hlx files) on a reference preset.
+// Without write it only previews the trims; with write the files are rewritten in place.
+func (a *App) GxMatchSetlist(paths []string, reference string, write bool) (*helix.SetlistReport, error) {
+	if len(paths) == 0 || reference == "" {
+		return nil, fmt.Errorf("no presets selected")
+	}
+	cfg := a.config.Get()
+	db, err := helix.CatalogFor(cfg.FirmwareVersion)
+	if err != nil {
+		return nil, err
+	}
+	report, err := db.MatchSetlistFiles(paths, reference, a.gainStaging(cfg), write)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// gainStaging returns the level normalization options for the configured target level
+func (a *App) gainStaging(cfg config.AppConfig) helix.GainStaging {
+	opts := helix.DefaultGainStaging
+	opts.TargetDB = cfg.TargetLevelDB
+	return opts
+}
+
+// GxSetBlockParam changes a block parameter without calling the agents (snapshot < 0 = base value)
+func (a *App) GxSetBlockParam(preset helix.Preset, ref helix.BlockRef, param string, value interface{}, snapshot int) (*helix.EditResult, error) {
+	return a.editPreset(preset, func(e *helix.Editor) error {
+		return e.SetParam(ref, param, value, snapshot)
+	})
+}
+
+// GxSetBlockSnapshotBypass turns a block on or off in a snapshot
+func (a *App) GxSetBlockSnapshotBypass(preset helix.Preset, ref helix.BlockRef, snapshot int, enabled bool) (*helix.EditResult, error) {
+	return a.editPreset(preset, func(e *helix.Editor) error {
+		return e.SetSnapshotBypass(ref, snapshot, enabled)
+	})
+}
+
+// GxSwapBlockModel replaces the model of a block, keeping the parameters both models share
+func (a *App) GxSwapBlockModel(preset helix.Preset, ref helix.BlockRef, model string) (*helix.EditResult, error) {
+	var dropped []string
+	result, err := a.editPreset(preset, func(e *helix.Editor) (err error) {
+		dropped, err = e.SwapModel(ref, model)
+		return err
+	})
+	if result != nil {
+		result.Dropped = dropped
+	}
+	return result, err
+}
+
+// GxMoveBlock moves a block to a position of a path (position < 0 = end of the path)
+func (a *App) GxMoveBlock(preset helix.Preset, ref helix.BlockRef, path int, position int) (*helix.EditResult, error) {
+	return a.editPreset(preset, func(e *helix.Editor) error {
+		_, err := e.MoveBlock(ref, path, position)
+		return err
+	})
+}
+
+// GxDeleteBlock removes a block with its snapshot states and controllers
+func (a *App) GxDeleteBlock(preset helix.Preset, ref helix.BlockRef) (*helix.EditResult, error) {
+	return a.editPreset(preset, func(e *helix.Editor) error {
+		return e.DeleteBlock(ref)
+	})
+}
+
+// GxImportHLXFolder learns unknown models from a folder of HX Edit exports and adds them to the overlay catalog
+func (a *App) GxImportHLXFolder(dir string) (*helix.ImportReport, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("no folder selected")
+	}
+	entries, report, err := helix.LearnFromHLXFolder(dir, &helix.DB)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		if err := helix.SaveOverlay(a.overlayPath(), entries); err != nil {
+			return nil, fmt.Errorf("failed to save overlay catalog: %v", err)
+		}
+		helix.ApplyOverlay(entries)
+	}
+	return &report, nil
 }
 
 // GxSaveFile saves the preset to the disk and returns the full path
@@ -114,6 +484,8 @@ func (a *App) GxSaveFile(preset helix.Preset, filename string) (string, error) {
 		}
 	}
 
+	// Presets of older chats still carry UI data in their meta
+	preset.RemoveUIData()
 	data, err := json.MarshalIndent(preset, "", "  ")
 	if err != nil {
 		return "", err
@@ -166,6 +538,15 @@ func (a *App) GxSelectFolder(initialDir string) (string, error) {
 	})
 }
 
+// GxSelectPresetFiles opens a dialog to pick .hlx presets
+func (a *App) GxSelectPresetFiles(initialDir string) ([]string, error) {
+	return runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		DefaultDirectory: initialDir,
+		Title:            "Select Presets",
+		Filters:          []runtime.FileFilter{{DisplayName: "Helix Presets (*.hlx)", Pattern: "*.hlx"}},
+	})
+}
+
 // GxGetDefaultOutputPath returns the default Documents/helaix path
 func (a *App) GxGetDefaultOutputPath() string {
 	// Use HOME for macOS/Linux, USERPROFILE for Windows
@@ -0,0 +1,56 @@
+// hlxlevel matches the volume of a setlist of .hlx presets on a reference preset, rewriting the files in place.
+//
+//	go run ./cmd/hlxlevel -reference gig/clean.hlx -write gig/
+//
+// Without -write, it only prints the estimated levels and the trims it would apply.
+package main
+
+import (
+	"HelAIx/pkg/helix"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	reference := flag.String("reference", "", "preset the others are matched to")
+	firmware := flag.String("firmware", helix.LatestFirmware, "target firmware")
+	tolerance := flag.Float64("tolerance", helix.DefaultGainStaging.ToleranceDB, "snapshots within this many dB of the reference are left alone")
+	write := flag.Bool("write", false, "rewrite the presets")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+	if flag.NArg() == 0 || *reference == "" {
+		fmt.Fprintln(os.Stderr, "usage: hlxlevel -reference preset.hlx [-firmware version] [-tolerance dB] [-write] [-json] preset.hlx|folder ...")
+		os.Exit(2)
+	}
+
+	db, err := helix.CatalogFor(*firmware)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	opts := helix.DefaultGainStaging
+	opts.ToleranceDB = *tolerance
+	report, err := db.MatchSetlistFiles(flag.Args(), *reference, opts, *write)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		return
+	}
+	fmt.Printf("reference %s: %+.1f dB\n", report.Reference, report.ReferenceDB)
+	for _, p := range report.Presets {
+		if p.Error != "" {
+			fmt.Printf("%s: %s\n", p.File, p.Error)
+			continue
+		}
+		fmt.Printf("%s (%s): %+.1f dB, trim %+.1f dB\n", p.File, p.Name, p.EstimatedDB, p.TrimDB)
+	}
+}
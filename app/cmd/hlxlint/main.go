@@ -0,0 +1,75 @@
+// hlxlint validates .hlx presets without the app.
+//
+//	go run ./cmd/hlxlint -hardware "HX Stomp" -firmware 3.80 preset.hlx ...
+//
+// It exits with status 1 when a preset has errors.
+package main
+
+import (
+	"HelAIx/pkg/helix"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	hardware := flag.String("hardware", "Helix Floor", "target hardware")
+	firmware := flag.String("firmware", helix.LatestFirmware, "target firmware")
+	asJSON := flag.Bool("json", false, "print the diagnostics as JSON")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: hlxlint [-hardware name] [-firmware version] [-json] preset.hlx ...")
+		os.Exit(2)
+	}
+
+	db, err := helix.CatalogFor(*firmware)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	failed := false
+	report := make(map[string][]helix.Diagnostic)
+	for _, path := range flag.Args() {
+		diags, err := lint(db, path, *hardware)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		failed = failed || helix.HasErrors(diags)
+		if *asJSON {
+			report[path] = diags
+			continue
+		}
+		for _, d := range diags {
+			fmt.Printf("%s: %s: %s: %s [%s]", path, d.Severity, d.Location, d.Message, d.Code)
+			if d.Fix != "" {
+				fmt.Printf(" (%s)", d.Fix)
+			}
+			fmt.Println()
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func lint(db *helix.CatalogDB, path, hardware string) ([]helix.Diagnostic, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var preset helix.Preset
+	if err := json.Unmarshal(data, &preset); err != nil {
+		return nil, fmt.Errorf("not a valid .hlx file: %v", err)
+	}
+	return db.Validate(&preset, hardware), nil
+}
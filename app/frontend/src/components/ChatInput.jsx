@@ -1,9 +1,11 @@
 import React, { useState, useRef } from 'react';
 import { useI18n } from '../i18n';
+import ModelPicker from './ModelPicker';
 
 const ChatInput = ({ onSend, loading }) => {
     const { t } = useI18n();
     const [text, setText] = useState('');
+    const [pickerOpen, setPickerOpen] = useState(false);
     const textareaRef = useRef(null);
 
     const handleSend = () => {
@@ -32,6 +34,13 @@ const ChatInput = ({ onSend, loading }) => {
         }
     };
 
+    // Hand-picked models are inserted by name so the agents can use them
+    const handlePick = (model) => {
+        setText(prev => (prev.trim() ? `${prev.trimEnd()} ` : '') + `"${model.name}"`);
+        setPickerOpen(false);
+        textareaRef.current?.focus();
+    };
+
     return (
         <div className="relative bg-white dark:bg-surface-dark rounded-xl border border-slate-300 dark:border-[#3f5256] focus-within:border-primary/50 focus-within:ring-1 focus-within:ring-primary/50 transition-all shadow-lg overflow-hidden">
             <textarea
@@ -39,11 +48,18 @@ const ChatInput = ({ onSend, loading }) => {
                 value={text}
                 onChange={handleChange}
                 onKeyDown={handleKeyDown}
-                className="w-full bg-transparent text-slate-900 dark:text-white placeholder-slate-400 dark:placeholder-text-muted text-sm p-4 pr-12 rounded-xl focus:outline-none resize-none font-body transition-all"
+                className="w-full bg-transparent text-slate-900 dark:text-white placeholder-slate-400 dark:placeholder-text-muted text-sm p-4 pr-24 rounded-xl focus:outline-none resize-none font-body transition-all"
                 placeholder={t('chat.placeholder')}
                 rows="1"
                 style={{ minHeight: '56px' }}
             />
+            <button
+                onClick={() => setPickerOpen(true)}
+                title={t('modelPicker.browse')}
+                className="absolute right-12 bottom-2 p-2 rounded-lg text-slate-400 dark:text-text-muted hover:text-primary hover:bg-primary/10 transition-all flex items-center justify-center"
+            >
+                <span className="material-symbols-outlined text-[20px]">library_music</span>
+            </button>
             <button
                 onClick={handleSend}
                 disabled={!text.trim() || loading}
@@ -56,6 +72,7 @@ const ChatInput = ({ onSend, loading }) => {
                     {loading ? 'sync' : 'arrow_upward'}
                 </span>
             </button>
+            <ModelPicker isOpen={pickerOpen} onClose={() => setPickerOpen(false)} onPick={handlePick} />
         </div>
     );
 };
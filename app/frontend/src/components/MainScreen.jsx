@@ -1,6 +1,7 @@
 import React from 'react';
-import { GxChatSoundEngineer, GxChatPresetEngineer, GxSaveFile } from '../../wailsjs/go/main/App';
-import { useI18n } from '../i18n';
+import { GxChatSoundEngineer, GxChatPresetEngineer, GxCancelRequest, GxSaveFile } from '../../wailsjs/go/main/App';
+import { EventsOn } from '../../wailsjs/runtime/runtime';
+import { useI18n, errorMessage } from '../i18n';
 import DesignVisualizer from './DesignVisualizer';
 import MessageVisualizer from './MessageVisualizer';
 import ChatInput from './ChatInput';
@@ -11,6 +12,8 @@ const MainScreen = ({ config, chatData, onUpdateChat, onNewChat }) => {
     const [loading, setLoading] = React.useState(false);
     const [showExportModal, setShowExportModal] = React.useState(false);
     const [lastExportPath, setLastExportPath] = React.useState('');
+    const [progress, setProgress] = React.useState(null);
+    const requestIdRef = React.useRef(null);
     const bottomRef = React.useRef(null);
 
     const messages = chatData?.messages || [];
@@ -34,7 +37,54 @@ const MainScreen = ({ config, chatData, onUpdateChat, onNewChat }) => {
 
     React.useEffect(() => {
         bottomRef.current?.scrollIntoView({ behavior: "smooth" });
-    }, [messages, loading]);
+    }, [messages, loading, progress]);
+
+    // Live progress streamed by the agents while loading
+    React.useEffect(() => {
+        if (!loading) {
+            setProgress(null);
+            return;
+        }
+        return EventsOn('gx:progress', (ev) => {
+            if (ev.request_id !== requestIdRef.current) return;
+            setProgress(prev => {
+                const next = { ...(prev || {}), stage: ev.stage };
+                if (prev?.stage !== ev.stage) {
+                    next.text = '';
+                    next.blocks = [];
+                    next.retry = 0;
+                }
+                if (ev.kind === 'partial' || (ev.kind === 'done' && ev.text)) {
+                    next.text = ev.text;
+                } else if (ev.kind === 'retry') {
+                    next.retry = ev.count;
+                } else if (ev.kind === 'block') {
+                    next.blocks = [...(next.blocks || []), ev.model || ev.text];
+                }
+                return next;
+            });
+        });
+    }, [loading]);
+
+    // Each agent call gets its own ID so it can be cancelled
+    const newRequestId = () => {
+        requestIdRef.current = `${Date.now()}-${Math.random().toString(36).slice(2, 8)}`;
+        return requestIdRef.current;
+    };
+
+    const requestOptions = (bypassCache = false) => ({
+        request_id: newRequestId(),
+        chat_id: chatData.id,
+        bypass_cache: bypassCache
+    });
+
+    const isCancelled = (err) => String(err).includes('request cancelled');
+
+    const handleCancel = () => {
+        if (requestIdRef.current) {
+            GxCancelRequest(requestIdRef.current);
+        }
+    };
 
     const formatHistory = (msgs) => {
         return msgs.map(m => ({
@@ -64,7 +114,7 @@ const MainScreen = ({ config, chatData, onUpdateChat, onNewChat }) => {
 
         try {
             if (stage === 'design') {
-                const design = await GxChatSoundEngineer(formatHistory(updatedMessages));
+                const design = await GxChatSoundEngineer(requestOptions(), formatHistory(updatedMessages));
                 const aiMsg = {
                     id: Date.now() + 1,
                     role: 'assistant',
@@ -79,12 +129,13 @@ const MainScreen = ({ config, chatData, onUpdateChat, onNewChat }) => {
             } else {
                 const latestDesign = [...messages].reverse().find(m => m.design)?.design;
                 const presetName = latestDesign?.suggested_name || "HelAIx Preset";
-                const preset = await GxChatPresetEngineer(latestDesign, presetName, formatHistory(updatedMessages));
+                const result = await GxChatPresetEngineer(requestOptions(), latestDesign, presetName, formatHistory(updatedMessages));
                 const aiMsg = {
                     id: Date.now() + 1,
                     role: 'assistant',
                     agent: 'preset_engineer',
-                    preset: preset,
+                    preset: result.preset,
+                    presetView: result.view,
                     content: "I've refined the technical preset based on your feedback."
                 };
                 onUpdateChat(chat => ({
@@ -93,35 +144,38 @@ const MainScreen = ({ config, chatData, onUpdateChat, onNewChat }) => {
                 }));
             }
         } catch (err) {
+            if (isCancelled(err)) return;
             onUpdateChat(chat => ({
                 ...chat,
                 messages: [...updatedMessages, {
                     id: Date.now() + 2,
                     role: 'assistant',
-                    error: "AI failed: " + err
+                    error: "AI failed: " + errorMessage(err, t)
                 }]
             }));
         } finally {
+            requestIdRef.current = null;
             setLoading(false);
         }
     };
 
-    const handleBuildPreset = async (messageId, design) => {
+    const handleBuildPreset = async (messageId, design, bypassCache = false) => {
         setLoading(true);
         try {
             const presetName = design.suggested_name || "HelAIx Preset";
-            const preset = await GxChatPresetEngineer(design, presetName, []);
+            const result = await GxChatPresetEngineer(requestOptions(bypassCache), design, presetName, []);
 
             onUpdateChat(chat => ({
                 ...chat,
                 stage: 'build',
                 messages: chat.messages.map(m =>
-                    m.id === messageId ? { ...m, preset: preset } : m
+                    m.id === messageId ? { ...m, preset: result.preset, presetView: result.view } : m
                 )
             }));
         } catch (err) {
-            alert("Build failed: " + err);
+            if (!isCancelled(err)) alert("Build failed: " + errorMessage(err, t));
         } finally {
+            requestIdRef.current = null;
             setLoading(false);
         }
     };
@@ -240,10 +294,35 @@ const MainScreen = ({ config, chatData, onUpdateChat, onNewChat }) => {
                                 <div className="w-10 h-10 rounded-full bg-slate-100 dark:bg-surface-dark flex items-center justify-center border border-slate-300 dark:border-border-dark">
                                     <span className="material-symbols-outlined text-primary text-xl animate-spin">sync</span>
                                 </div>
-                                <div className="bg-slate-100 dark:bg-border-dark p-4 rounded-2xl rounded-bl-none flex items-center gap-2">
-                                    <span className="w-2 h-2 bg-primary rounded-full animate-bounce" style={{ animationDelay: '0ms' }}></span>
-                                    <span className="w-2 h-2 bg-primary rounded-full animate-bounce" style={{ animationDelay: '150ms' }}></span>
-                                    <span className="w-2 h-2 bg-primary rounded-full animate-bounce" style={{ animationDelay: '300ms' }}></span>
+                                <div className="bg-slate-100 dark:bg-border-dark p-4 rounded-2xl rounded-bl-none flex flex-col gap-2 max-w-[85%]">
+                                    {progress?.stage && (
+                                        <span className="text-primary text-[10px] font-bold uppercase tracking-widest">
+                                            {t(progress.stage === 'preset_engineer' ? 'chat.progress.building' : 'chat.progress.designing')}
+                                        </span>
+                                    )}
+                                    {progress?.retry > 0 && !progress?.text && (
+                                        <p className="text-slate-500 dark:text-text-secondary text-xs italic">{t('chat.progress.retrying')} ({progress.retry})</p>
+                                    )}
+                                    {progress?.text && <p className="whitespace-pre-wrap break-words text-[15px] text-slate-900 dark:text-white/90">{progress.text}</p>}
+                                    {progress?.blocks?.length > 0 && (
+                                        <p className="text-slate-500 dark:text-text-secondary text-xs">
+                                            {t('chat.progress.mapped')}: {progress.blocks.join(' → ')}
+                                        </p>
+                                    )}
+                                    <div className="flex items-center gap-2">
+                                        <span className="w-2 h-2 bg-primary rounded-full animate-bounce" style={{ animationDelay: '0ms' }}></span>
+                                        <span className="w-2 h-2 bg-primary rounded-full animate-bounce" style={{ animationDelay: '150ms' }}></span>
+                                        <span className="w-2 h-2 bg-primary rounded-full animate-bounce" style={{ animationDelay: '300ms' }}></span>
+                                        {requestIdRef.current && (
+                                            <button
+                                                onClick={handleCancel}
+                                                className="ml-4 flex items-center gap-1 text-xs text-slate-500 dark:text-text-muted hover:text-red-400 transition-colors"
+                                            >
+                                                <span className="material-symbols-outlined text-[16px]">stop_circle</span>
+                                                {t('chat.cancel')}
+                                            </button>
+                                        )}
+                                    </div>
                                 </div>
                             </div>
                         )}
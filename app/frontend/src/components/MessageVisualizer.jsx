@@ -51,18 +51,30 @@ const MessageVisualizer = ({ msg, messages, onBuildPreset, onExportHlx }) => {
                 <div className="space-y-4 w-full pt-4 border-t border-slate-300 dark:border-indigo-800/50">
                     <PresetVisualizer
                         preset={msg.preset}
+                        view={msg.presetView}
                         design={msg.design || messages.findLast(m => m.design && m.id < msg.id)?.design}
                         activeSnapIdx={activeSnapIdx}
                         compact={true}
                         hideSelector={true} // New prop to hide internal selector
                     />
-                    <button
-                        onClick={() => onExportHlx(msg.preset)}
-                        className="w-full bg-primary hover:bg-[#0fb3d4] text-slate-900 h-10 px-4 rounded-lg flex items-center justify-center gap-2 font-bold transition-all shadow-lg shadow-primary/10"
-                    >
-                        <span className="material-symbols-outlined text-[18px]">download</span>
-                        {t('chat.exportBtn')}
-                    </button>
+                    <div className="flex gap-2">
+                        <button
+                            onClick={() => onExportHlx(msg.preset)}
+                            className="flex-1 bg-primary hover:bg-[#0fb3d4] text-slate-900 h-10 px-4 rounded-lg flex items-center justify-center gap-2 font-bold transition-all shadow-lg shadow-primary/10"
+                        >
+                            <span className="material-symbols-outlined text-[18px]">download</span>
+                            {t('chat.exportBtn')}
+                        </button>
+                        {msg.design && (
+                            <button
+                                onClick={() => onBuildPreset(msg.id, msg.design, true)}
+                                title={t('chat.regenerateBtn')}
+                                className="h-10 px-3 rounded-lg border border-slate-300 dark:border-border-dark text-slate-600 dark:text-text-muted hover:text-primary hover:border-primary/50 flex items-center justify-center transition-all"
+                            >
+                                <span className="material-symbols-outlined text-[18px]">refresh</span>
+                            </button>
+                        )}
+                    </div>
                 </div>
             )}
         </div>
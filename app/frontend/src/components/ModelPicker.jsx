@@ -0,0 +1,90 @@
+import React, { useState, useEffect } from 'react';
+import { useI18n } from '../i18n';
+import { GxSearchCatalog } from '../../wailsjs/go/main/App';
+
+const CATEGORIES = ["amp", "cab", "distortion", "dynamics", "eq", "modulation", "delay", "reverb", "pitch_synth", "filter", "wah", "volume"];
+
+const ModelPicker = ({ isOpen, onClose, onPick }) => {
+    const { t } = useI18n();
+    const [text, setText] = useState('');
+    const [category, setCategory] = useState('');
+    const [results, setResults] = useState([]);
+
+    // Search as the user types (typo tolerant, filtered by category)
+    useEffect(() => {
+        if (!isOpen) return;
+        const timer = setTimeout(() => {
+            GxSearchCatalog({ text, category, max_dsp: 0, limit: 40 })
+                .then(r => setResults(r || []))
+                .catch(() => setResults([]));
+        }, 150);
+        return () => clearTimeout(timer);
+    }, [isOpen, text, category]);
+
+    if (!isOpen) return null;
+
+    return (
+        <div className="fixed inset-0 z-50 flex items-center justify-center p-4 bg-black/60 backdrop-blur-sm animate-in fade-in duration-300" onClick={onClose}>
+            <div
+                className="w-full max-w-2xl max-h-[80vh] flex flex-col bg-white dark:bg-surface-dark border border-slate-300 dark:border-[#3f5256] rounded-2xl shadow-2xl overflow-hidden animate-in zoom-in duration-300"
+                onClick={(e) => e.stopPropagation()}
+            >
+                <div className="p-6 flex flex-col gap-4 border-b border-slate-200 dark:border-border-dark">
+                    <div className="flex items-center justify-between">
+                        <h3 className="text-xl font-bold text-slate-900 dark:text-white font-display">{t('modelPicker.title')}</h3>
+                        <button onClick={onClose} className="text-slate-400 hover:text-slate-900 dark:hover:text-white">
+                            <span className="material-symbols-outlined">close</span>
+                        </button>
+                    </div>
+                    <div className="flex gap-3">
+                        <input
+                            type="text"
+                            autoFocus
+                            value={text}
+                            placeholder={t('modelPicker.search')}
+                            onChange={(e) => setText(e.target.value)}
+                            className="flex-1 rounded-lg border border-border-light dark:border-border-dark bg-surface-light dark:bg-background-dark px-4 h-11 text-sm focus:outline-none focus:ring-2 focus:ring-primary/50"
+                        />
+                        <select
+                            value={category}
+                            onChange={(e) => setCategory(e.target.value)}
+                            className="rounded-lg border border-border-light dark:border-border-dark bg-surface-light dark:bg-background-dark px-3 h-11 text-sm focus:outline-none focus:ring-2 focus:ring-primary/50 cursor-pointer"
+                        >
+                            <option value="">{t('modelPicker.allCategories')}</option>
+                            {CATEGORIES.map(c => (
+                                <option key={c} value={c}>{t(`modelPicker.categories.${c}`)}</option>
+                            ))}
+                        </select>
+                    </div>
+                </div>
+
+                <div className="flex-1 overflow-y-auto">
+                    {results.length === 0 ? (
+                        <p className="p-6 text-sm text-text-muted text-center">{t('modelPicker.empty')}</p>
+                    ) : (
+                        <ul className="divide-y divide-slate-200 dark:divide-border-dark">
+                            {results.map(r => (
+                                <li key={r.internal_name}>
+                                    <button
+                                        onClick={() => onPick(r)}
+                                        className="w-full flex items-center justify-between gap-4 px-6 py-3 text-left hover:bg-primary/10 transition-colors"
+                                    >
+                                        <div className="min-w-0">
+                                            <p className="text-sm font-bold text-slate-900 dark:text-white truncate">{r.name}</p>
+                                            <p className="text-xs text-text-muted truncate">
+                                                {t(`modelPicker.categories.${r.category}`)}{r.based_on ? ` · ${r.based_on}` : ''}
+                                            </p>
+                                        </div>
+                                        <span className="text-xs font-mono text-text-muted shrink-0">{r.dsp_mono.toFixed(1)}% DSP</span>
+                                    </button>
+                                </li>
+                            ))}
+                        </ul>
+                    )}
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default ModelPicker;
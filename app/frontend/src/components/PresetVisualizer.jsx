@@ -1,11 +1,21 @@
-import React, { useState } from 'react';
+import React, { useState, useEffect } from 'react';
 import { useI18n } from '../i18n';
-import SignalChain from './SignalChain';
+import SignalChain, { isSnapshotController } from './SignalChain';
+import { GxValidatePreset } from '../../wailsjs/go/main/App';
 
-function PresetVisualizer({ preset, design, compact = false, activeSnapIdx: propsActiveSnapIdx, hideSelector = false }) {
+function PresetVisualizer({ preset, view, design, compact = false, activeSnapIdx: propsActiveSnapIdx, hideSelector = false }) {
     const { t } = useI18n();
     const [localActiveSnapIdx, setLocalActiveSnapIdx] = useState(0);
 
+    const [diagnostics, setDiagnostics] = useState([]);
+
+    useEffect(() => {
+        if (!preset) return;
+        GxValidatePreset(preset)
+            .then(d => setDiagnostics(d || []))
+            .catch(err => console.error("Validation failed:", err));
+    }, [preset]);
+
     const activeSnapIdx = propsActiveSnapIdx !== undefined ? propsActiveSnapIdx : localActiveSnapIdx;
     const setActiveSnapIdx = propsActiveSnapIdx !== undefined ? () => { } : setLocalActiveSnapIdx;
 
@@ -32,7 +42,7 @@ function PresetVisualizer({ preset, design, compact = false, activeSnapIdx: prop
 
                 // Technical Check: Does this block have snapshot-controlled parameters?
                 const controllers = preset.data.tone.controller?.[dspKey]?.[key] || {};
-                const hasTechnicalShift = Object.values(controllers).some(c => c["@controller"] === 9);
+                const hasTechnicalShift = Object.values(controllers).some(c => isSnapshotController(c["@controller"]));
 
                 // Abstract Check: Did the AI mention a parameter shift here?
                 let hasDesignShift = false;
@@ -93,7 +103,8 @@ function PresetVisualizer({ preset, design, compact = false, activeSnapIdx: prop
 
     if (hasVariaxData || hasVariaxControllers) {
         const modelId = activeVariax?.["@variax_model"] || 0;
-        const variaxType = preset.data.meta?.variax_type || "jtv";
+        // Presets of older chats carry their UI data in meta
+        const variaxType = view?.variax_type || preset.data.meta?.variax_type || "jtv";
         const getName = (id) => {
             if (id === 0) return "Variax";
             const jtvBanks = ["Custom 1", "T-Model", "Spank", "Lester", "Special", "R-Billy", "Chime", "Semi", "Jazzbox", "Acoustic", "Reso", "Custom 2"];
@@ -160,10 +171,40 @@ function PresetVisualizer({ preset, design, compact = false, activeSnapIdx: prop
                 </div>
 
                 <div className="w-full">
-                    <SignalChain dspBlocks={dspBlocks} activeSnapshot={activeSnapshot} preset={preset} />
+                    <SignalChain dspBlocks={dspBlocks} activeSnapshot={activeSnapshot} preset={preset} dspMap={view?.dsp_map || preset.data.meta?.dsp_map || {}} />
                 </div>
             </div>
 
+            {/* Preset Check (helix.Validate) */}
+            {!compact && (
+                <details className="group pt-1" open={diagnostics.some(d => d.severity === 'error')}>
+                    <summary className="cursor-pointer text-text-muted hover:text-white text-[10px] uppercase font-bold tracking-widest list-none flex items-center justify-center gap-1 transition-colors opacity-60 hover:opacity-100">
+                        <span className={`material-symbols-outlined text-[14px] ${diagnostics.some(d => d.severity === 'error') ? 'text-red-400' : diagnostics.length > 0 ? 'text-amber-400' : 'text-primary'}`}>
+                            {diagnostics.length > 0 ? 'report' : 'verified'}
+                        </span>
+                        {t('chat.validation.title')}
+                        {diagnostics.length > 0
+                            ? ` · ${diagnostics.filter(d => d.severity === 'error').length} ${t('chat.validation.errors')}, ${diagnostics.filter(d => d.severity === 'warning').length} ${t('chat.validation.warnings')}`
+                            : ` · ${t('chat.validation.ok')}`}
+                    </summary>
+                    {diagnostics.length > 0 && (
+                        <ul className="mt-2 space-y-1 bg-[#0b1011] p-3 rounded-lg border border-[#283639] text-[11px]">
+                            {diagnostics.map((d, i) => (
+                                <li key={i} className="flex gap-2">
+                                    <span className={`material-symbols-outlined text-[14px] ${d.severity === 'error' ? 'text-red-400' : 'text-amber-400'}`}>
+                                        {d.severity === 'error' ? 'error' : 'warning'}
+                                    </span>
+                                    <span className="text-slate-300">
+                                        <span className="font-mono text-text-muted">{d.location}</span> {d.message}
+                                        {d.fix && <span className="text-text-muted"> ({d.fix})</span>}
+                                    </span>
+                                </li>
+                            ))}
+                        </ul>
+                    )}
+                </details>
+            )}
+
             {/* Raw JSON Debug */}
             <details className="group pt-1">
                 <summary className="cursor-pointer text-text-muted hover:text-white text-[10px] uppercase font-bold tracking-widest list-none flex items-center justify-center gap-1 transition-colors opacity-40 hover:opacity-100">
@@ -0,0 +1,138 @@
+import React, { useState } from 'react';
+import { useI18n } from '../i18n';
+import { GxSelectPresetFiles, GxMatchSetlist } from '../../wailsjs/go/main/App';
+
+const baseName = (path) => path.split(/[\\/]/).pop();
+
+// SetlistMatcher previews the volume matching of a set of presets, and only rewrites the files once confirmed
+const SetlistMatcher = ({ isOpen, onClose, initialDir }) => {
+    const { t } = useI18n();
+    const [files, setFiles] = useState([]);
+    const [reference, setReference] = useState('');
+    const [report, setReport] = useState(null);
+    const [applied, setApplied] = useState(false);
+    const [busy, setBusy] = useState(false);
+
+    if (!isOpen) return null;
+
+    const handleChoose = async () => {
+        try {
+            const picked = await GxSelectPresetFiles(initialDir || '');
+            if (!picked || picked.length === 0) return;
+            setFiles(picked);
+            setReference(picked[0]);
+            setReport(null);
+            setApplied(false);
+        } catch (err) {
+            alert(err);
+        }
+    };
+
+    const run = async (write) => {
+        setBusy(true);
+        try {
+            setReport(await GxMatchSetlist(files, reference, write));
+            setApplied(write);
+        } catch (err) {
+            alert(err);
+        } finally {
+            setBusy(false);
+        }
+    };
+
+    const handleApply = () => {
+        if (window.confirm(t('setlist.confirm').replace('{count}', files.length - 1))) {
+            run(true);
+        }
+    };
+
+    const formatDB = (db) => `${db > 0 ? '+' : ''}${db.toFixed(1)} dB`;
+
+    return (
+        <div className="fixed inset-0 z-50 flex items-center justify-center p-4 bg-black/60 backdrop-blur-sm animate-in fade-in duration-300" onClick={onClose}>
+            <div
+                className="w-full max-w-2xl max-h-[80vh] flex flex-col bg-white dark:bg-surface-dark border border-slate-300 dark:border-[#3f5256] rounded-2xl shadow-2xl overflow-hidden animate-in zoom-in duration-300"
+                onClick={(e) => e.stopPropagation()}
+            >
+                <div className="p-6 flex flex-col gap-4 border-b border-slate-200 dark:border-border-dark">
+                    <div className="flex items-center justify-between">
+                        <h3 className="text-xl font-bold text-slate-900 dark:text-white font-display">{t('setlist.title')}</h3>
+                        <button onClick={onClose} className="text-slate-400 hover:text-slate-900 dark:hover:text-white">
+                            <span className="material-symbols-outlined">close</span>
+                        </button>
+                    </div>
+                    <p className="text-sm text-text-muted">{t('setlist.description')}</p>
+                    <div className="flex gap-3">
+                        <button
+                            onClick={handleChoose}
+                            className="px-4 h-11 rounded-lg border border-border-light dark:border-border-dark text-sm font-medium hover:border-primary hover:text-primary transition-colors"
+                        >
+                            {t('setlist.choose')}
+                        </button>
+                        <select
+                            value={reference}
+                            disabled={files.length === 0}
+                            onChange={(e) => { setReference(e.target.value); setReport(null); setApplied(false); }}
+                            className="flex-1 rounded-lg border border-border-light dark:border-border-dark bg-surface-light dark:bg-background-dark px-3 h-11 text-sm focus:outline-none focus:ring-2 focus:ring-primary/50 cursor-pointer disabled:opacity-50"
+                        >
+                            {files.length === 0 && <option value="">{t('setlist.noFiles')}</option>}
+                            {files.map(f => (
+                                <option key={f} value={f}>{t('setlist.reference')}: {baseName(f)}</option>
+                            ))}
+                        </select>
+                    </div>
+                </div>
+
+                <div className="flex-1 overflow-y-auto px-6 py-4">
+                    {!report ? (
+                        <p className="text-sm text-text-muted text-center">{t('setlist.empty')}</p>
+                    ) : (
+                        <table className="w-full text-sm">
+                            <thead>
+                                <tr className="text-text-muted text-xs text-left">
+                                    <th className="py-1">{t('setlist.preset')}</th>
+                                    <th className="py-1 text-right">{t('setlist.level')}</th>
+                                    <th className="py-1 text-right">{t('setlist.trim')}</th>
+                                </tr>
+                            </thead>
+                            <tbody>
+                                {report.presets.map(p => (
+                                    <tr key={p.file} className="border-t border-border-light dark:border-border-dark">
+                                        <td className="py-1">
+                                            <p className="truncate">{p.name || p.file}</p>
+                                            {p.error && <p className="text-xs text-red-500">{p.error}</p>}
+                                        </td>
+                                        <td className="py-1 text-right font-mono">{p.error ? '' : formatDB(p.estimated_db)}</td>
+                                        <td className="py-1 text-right font-mono">
+                                            {p.file === report.reference ? t('setlist.isReference') : (p.error ? '' : formatDB(p.trim_db))}
+                                        </td>
+                                    </tr>
+                                ))}
+                            </tbody>
+                        </table>
+                    )}
+                </div>
+
+                <div className="p-6 flex items-center justify-end gap-3 border-t border-slate-200 dark:border-border-dark">
+                    {applied && <p className="flex-1 text-sm text-primary">{t('setlist.done')}</p>}
+                    <button
+                        onClick={() => run(false)}
+                        disabled={busy || files.length < 2}
+                        className="px-4 h-10 rounded-lg border border-border-light dark:border-border-dark text-sm font-medium hover:border-primary hover:text-primary transition-colors disabled:opacity-50"
+                    >
+                        {t('setlist.preview')}
+                    </button>
+                    <button
+                        onClick={handleApply}
+                        disabled={busy || !report || applied}
+                        className="px-4 h-10 rounded-lg bg-primary text-background-dark text-sm font-bold hover:bg-primary/90 transition-colors disabled:opacity-50"
+                    >
+                        {t('setlist.apply')}
+                    </button>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default SetlistMatcher;
@@ -1,7 +1,8 @@
 import React, { useState, useEffect } from 'react';
 import { useI18n } from '../i18n';
-import { GxSaveConfig, GxTestConnection, GxSelectFolder, GxGetDefaultOutputPath, GxListModels } from '../../wailsjs/go/main/App';
+import { GxSaveConfig, GxTestConnection, GxSelectFolder, GxGetDefaultOutputPath, GxListModels, GxGetSupportedFirmware, GxGetUsage, GxClearResponseCache } from '../../wailsjs/go/main/App';
 import { HelixIcons } from './IconLibrary';
+import SetlistMatcher from './SetlistMatcher';
 
 const Settings = ({ config, onSave }) => {
     const { t, lang, changeLang } = useI18n();
@@ -12,6 +13,11 @@ const Settings = ({ config, onSave }) => {
     const [defaultPath, setDefaultPath] = useState('');
     const [availableModels, setAvailableModels] = useState([]);
     const [loadingModels, setLoadingModels] = useState(false);
+    const [firmwareVersions, setFirmwareVersions] = useState([]);
+    const [usage, setUsage] = useState(null);
+    const [showSetlist, setShowSetlist] = useState(false);
+    // Raw text of the fallback list, so typing a trailing comma isn't swallowed
+    const [fallbackText, setFallbackText] = useState((config.fallback_models || []).join(', '));
 
     useEffect(() => {
         const fetchDefault = async () => {
@@ -19,6 +25,8 @@ const Settings = ({ config, onSave }) => {
             setDefaultPath(def);
         };
         fetchDefault();
+        GxGetSupportedFirmware().then(setFirmwareVersions).catch(console.error);
+        GxGetUsage().then(setUsage).catch(console.error);
     }, []);
 
     useEffect(() => {
@@ -172,6 +180,29 @@ const Settings = ({ config, onSave }) => {
                         </label>
                     </div>
 
+                    <div className="px-4 py-2">
+                        <label className="flex flex-col flex-1 gap-2">
+                            <p className="text-base font-medium leading-normal">{t('settings.fallbackModels')}</p>
+                            <input
+                                type="text"
+                                value={fallbackText}
+                                onChange={(e) => {
+                                    setFallbackText(e.target.value);
+                                    setLocalConfig({
+                                        ...localConfig,
+                                        fallback_models: e.target.value.split(',').map(m => m.trim()).filter(m => m !== '')
+                                    });
+                                }}
+                                className="w-full rounded-lg border border-border-light dark:border-border-dark bg-surface-light dark:bg-surface-dark px-4 h-14 text-base focus:outline-none focus:ring-2 focus:ring-primary/50 focus:border-primary transition-all"
+                                placeholder="gemini-2.5-flash-lite, gemini-2.0-flash"
+                            />
+                            <p className="text-xs text-text-muted mt-1 flex items-center gap-1">
+                                <span className="material-symbols-outlined text-[14px]">info</span>
+                                {t('settings.fallbackModelsHint')}
+                            </p>
+                        </label>
+                    </div>
+
                     <div className="px-4 py-2">
                         <label className="flex flex-col flex-1 gap-2">
                             <p className="text-base font-medium leading-normal">{t('settings.apiKey')}</p>
@@ -220,6 +251,63 @@ const Settings = ({ config, onSave }) => {
                     </div>
                 </section>
 
+                {/* Usage Section */}
+                <section className="flex flex-col gap-4">
+                    <div className="px-4 pb-2 pt-4 border-b border-border-light dark:border-border-dark flex items-center gap-3">
+                        <span className="material-symbols-outlined text-primary">payments</span>
+                        <h2 className="text-xl font-bold leading-tight tracking-tight">{t('settings.usageSection')}</h2>
+                    </div>
+
+                    <div className="px-4 py-2 flex flex-col gap-3">
+                        {!usage || usage.total.requests === 0 ? (
+                            <p className="text-sm text-text-muted">{t('settings.usageEmpty')}</p>
+                        ) : (
+                            <>
+                                <div className="grid grid-cols-3 gap-3">
+                                    {[
+                                        [t('settings.usageToday'), usage.by_day[usage.days[0]]],
+                                        [t('settings.usageTotal'), usage.total],
+                                        [t('settings.usageRequests'), null]
+                                    ].map(([label, totals]) => (
+                                        <div key={label} className="rounded-lg border border-border-light dark:border-border-dark bg-surface-light dark:bg-surface-dark p-4">
+                                            <p className="text-xs text-text-muted">{label}</p>
+                                            <p className="text-xl font-bold">
+                                                {totals ? `$${totals.cost.toFixed(4)}${totals.priced ? '' : '*'}` : usage.total.requests}
+                                            </p>
+                                        </div>
+                                    ))}
+                                </div>
+                                <table className="w-full text-sm">
+                                    <thead>
+                                        <tr className="text-text-muted text-xs text-left">
+                                            <th className="py-1">{t('settings.usageDay')}</th>
+                                            <th className="py-1 text-right">{t('settings.usageTokensIn')}</th>
+                                            <th className="py-1 text-right">{t('settings.usageTokensOut')}</th>
+                                            <th className="py-1 text-right">{t('settings.usageCost')}</th>
+                                        </tr>
+                                    </thead>
+                                    <tbody>
+                                        {usage.days.slice(0, 7).map(day => {
+                                            const d = usage.by_day[day];
+                                            return (
+                                                <tr key={day} className="border-t border-border-light dark:border-border-dark">
+                                                    <td className="py-1 font-mono">{day}</td>
+                                                    <td className="py-1 text-right">{d.prompt_tokens.toLocaleString()}</td>
+                                                    <td className="py-1 text-right">{d.response_tokens.toLocaleString()}</td>
+                                                    <td className="py-1 text-right">${d.cost.toFixed(4)}{d.priced ? '' : '*'}</td>
+                                                </tr>
+                                            );
+                                        })}
+                                    </tbody>
+                                </table>
+                                {!usage.total.priced && (
+                                    <p className="text-xs text-text-muted">* {t('settings.usageUnpriced')}</p>
+                                )}
+                            </>
+                        )}
+                    </div>
+                </section>
+
                 {/* Export Section */}
                 <section className="flex flex-col gap-4">
                     <div className="px-4 pb-2 pt-4 border-b border-border-light dark:border-border-dark flex items-center gap-3">
@@ -247,6 +335,20 @@ const Settings = ({ config, onSave }) => {
                             </button>
                         </div>
                     </div>
+
+                    <label className="flex flex-col gap-2 px-4 py-2">
+                        <p className="text-base font-medium leading-normal">{t('settings.presetAuthor')}</p>
+                        <input
+                            type="text"
+                            value={localConfig.preset_author || ''}
+                            onChange={(e) => setLocalConfig({ ...localConfig, preset_author: e.target.value })}
+                            className="w-full rounded-lg border border-border-light dark:border-border-dark bg-surface-light dark:bg-surface-dark px-4 h-14 text-base focus:outline-none focus:ring-2 focus:ring-primary/50 focus:border-primary transition-all"
+                        />
+                        <p className="text-xs text-text-muted mt-1 flex items-center gap-1">
+                            <span className="material-symbols-outlined text-[14px]">info</span>
+                            {t('settings.presetAuthorHint')}
+                        </p>
+                    </label>
                 </section>
 
                 {/* Hardware Section */}
@@ -307,6 +409,28 @@ const Settings = ({ config, onSave }) => {
                                 {t('settings.defaultExpHint')}
                             </p>
                         </label>
+
+                        <label className="flex flex-col flex-1 gap-2">
+                            <p className="text-base font-medium leading-normal">{t('settings.firmwareVersion')}</p>
+                            <div className="relative">
+                                <select
+                                    value={localConfig.firmware_version}
+                                    onChange={(e) => setLocalConfig({ ...localConfig, firmware_version: e.target.value })}
+                                    className="w-full appearance-none rounded-lg border border-border-light dark:border-border-dark bg-surface-light dark:bg-surface-dark px-4 h-14 text-base focus:outline-none focus:ring-2 focus:ring-primary/50 focus:border-primary transition-all cursor-pointer"
+                                >
+                                    {firmwareVersions.map(v => (
+                                        <option key={v} value={v}>{v}</option>
+                                    ))}
+                                </select>
+                                <div className="pointer-events-none absolute inset-y-0 right-0 flex items-center px-4 text-text-muted">
+                                    <span className="material-symbols-outlined">expand_more</span>
+                                </div>
+                            </div>
+                            <p className="text-xs text-text-muted mt-1 flex items-center gap-1">
+                                <span className="material-symbols-outlined text-[14px]">system_update</span>
+                                {t('settings.firmwareHint')}
+                            </p>
+                        </label>
                     </div>
                 </section>
 
@@ -424,6 +548,72 @@ const Settings = ({ config, onSave }) => {
                                     />
                                 </button>
                             </div>
+
+                            {/* Response Cache Toggle */}
+                            <div className="flex items-center justify-between p-4 bg-surface-light dark:bg-[#151c1e] rounded-xl border border-border-light dark:border-border-dark hover:border-primary/30 transition-all group">
+                                <div className="flex flex-col gap-0.5">
+                                    <p className="text-base font-medium text-slate-900 dark:text-white group-hover:text-primary transition-colors">{t('settings.cacheEnabled')}</p>
+                                    <p className="text-xs text-text-muted">{t('settings.cacheEnabledHint')}</p>
+                                </div>
+                                <div className="flex items-center gap-3">
+                                    <button
+                                        onClick={() => GxClearResponseCache().then(() => alert(t('settings.cacheCleared'))).catch(err => alert(err))}
+                                        className="text-xs text-text-muted hover:text-primary transition-colors"
+                                    >
+                                        {t('settings.cacheClear')}
+                                    </button>
+                                    <button
+                                        onClick={() => setLocalConfig({ ...localConfig, cache_enabled: !localConfig.cache_enabled })}
+                                        className={`relative inline-flex h-6 w-11 items-center rounded-full transition-colors focus:outline-none focus:ring-2 focus:ring-primary focus:ring-offset-2 dark:focus:ring-offset-background-dark ${localConfig.cache_enabled ? 'bg-primary' : 'bg-slate-300 dark:bg-border-dark'
+                                            }`}
+                                    >
+                                        <span
+                                            className={`inline-block h-4 w-4 transform rounded-full bg-white transition-transform ${localConfig.cache_enabled ? 'translate-x-6' : 'translate-x-1'
+                                                }`}
+                                        />
+                                    </button>
+                                </div>
+                            </div>
+
+                            {/* Gain Staging Toggle */}
+                            <div className="flex items-center justify-between p-4 bg-surface-light dark:bg-[#151c1e] rounded-xl border border-border-light dark:border-border-dark hover:border-primary/30 transition-all group">
+                                <div className="flex flex-col gap-0.5">
+                                    <p className="text-base font-medium text-slate-900 dark:text-white group-hover:text-primary transition-colors">{t('settings.gainStaging')}</p>
+                                    <p className="text-xs text-text-muted">{t('settings.gainStagingHint')}</p>
+                                </div>
+                                <div className="flex items-center gap-3">
+                                    <button
+                                        onClick={() => setShowSetlist(true)}
+                                        className="text-xs text-text-muted hover:text-primary transition-colors"
+                                    >
+                                        {t('setlist.open')}
+                                    </button>
+                                    <label className="flex items-center gap-1 text-xs text-text-muted">
+                                        {t('settings.targetLevel')}
+                                        <input
+                                            type="number"
+                                            step="0.5"
+                                            min="-12"
+                                            max="12"
+                                            disabled={!localConfig.gain_staging}
+                                            value={localConfig.target_level_db ?? 0}
+                                            onChange={(e) => setLocalConfig({ ...localConfig, target_level_db: parseFloat(e.target.value) || 0 })}
+                                            className="w-16 rounded-md border border-border-light dark:border-border-dark bg-surface-light dark:bg-surface-dark px-2 h-8 text-sm text-right focus:outline-none focus:ring-2 focus:ring-primary/50 disabled:opacity-50"
+                                        />
+                                        dB
+                                    </label>
+                                    <button
+                                        onClick={() => setLocalConfig({ ...localConfig, gain_staging: !localConfig.gain_staging })}
+                                        className={`relative inline-flex h-6 w-11 items-center rounded-full transition-colors focus:outline-none focus:ring-2 focus:ring-primary focus:ring-offset-2 dark:focus:ring-offset-background-dark ${localConfig.gain_staging ? 'bg-primary' : 'bg-slate-300 dark:bg-border-dark'
+                                            }`}
+                                    >
+                                        <span
+                                            className={`inline-block h-4 w-4 transform rounded-full bg-white transition-transform ${localConfig.gain_staging ? 'translate-x-6' : 'translate-x-1'
+                                                }`}
+                                        />
+                                    </button>
+                                </div>
+                            </div>
                         </div>
                     </div>
                 </section>
@@ -447,6 +637,12 @@ const Settings = ({ config, onSave }) => {
                     </div>
                 </div>
             </div>
+
+            <SetlistMatcher
+                isOpen={showSetlist}
+                onClose={() => setShowSetlist(false)}
+                initialDir={localConfig.output_path || defaultPath}
+            />
         </main>
     );
 };
@@ -1,7 +1,11 @@
-import React, { useState } from 'react';
+import React, { useState, useEffect } from 'react';
 import { getIconForBlock, getBlockColor } from './IconLibrary';
+import { GxFormatParams, GxGetModelParams } from '../../wailsjs/go/main/App';
 
-const BlockParameters = ({ block, blockKey, color, activeSnapshot, preset, onClose, rigTotal }) => {
+// Snapshot controller IDs: 19 as written by HX Edit, 9 in presets from older HelAIx builds
+export const isSnapshotController = (id) => id === 19 || id === 9;
+
+const BlockParameters = ({ block, blockKey, color, activeSnapshot, preset, dspMap, onClose, rigTotal }) => {
     // Determine the current value accounting for snapshot overrides
     const getParamValue = (pKey, baseVal) => {
         if (!preset || !preset.data || !preset.data.tone || !activeSnapshot) return baseVal;
@@ -30,7 +34,7 @@ const BlockParameters = ({ block, blockKey, color, activeSnapshot, preset, onClo
         const blockCtrls = ctrls[internalId];
         if (!blockCtrls) return false;
         const pCtrl = blockCtrls[pKey];
-        return pCtrl && pCtrl["@controller"] === 9;
+        return pCtrl && isSnapshotController(pCtrl["@controller"]);
     };
 
     const getVisibleParams = (block) => {
@@ -68,8 +72,34 @@ const BlockParameters = ({ block, blockKey, color, activeSnapshot, preset, onClo
 
     const params = getVisibleParams(block);
 
+    // Human-readable values ("375 ms", "-3.0 dB", "1/8.") and ranges from the catalog parameter metadata
+    const [display, setDisplay] = useState({});
+    const [ranges, setRanges] = useState({});
+    const modelId = block["@model"];
+    const currentValues = Object.fromEntries(params.map(([pKey, pVal]) => [pKey, getParamValue(pKey, pVal)]));
+    const valuesKey = JSON.stringify(currentValues);
+
+    useEffect(() => {
+        if (!modelId) return;
+        GxGetModelParams(modelId)
+            .then(infos => setRanges(Object.fromEntries((infos || []).map(p => [p.name, p]))))
+            .catch(() => setRanges({}));
+    }, [modelId]);
+
+    useEffect(() => {
+        if (!modelId) return;
+        GxFormatParams(modelId, currentValues)
+            .then(d => setDisplay(d || {}))
+            .catch(() => setDisplay({}));
+    }, [modelId, valuesKey]);
+
+    const fillPercent = (pKey, v) => {
+        const info = ranges[pKey];
+        if (!info || info.max <= info.min) return Math.min(100, Math.max(0, v * 100));
+        return Math.min(100, Math.max(0, (v - info.min) / (info.max - info.min) * 100));
+    };
+
     // DSP Calculation
-    const dspMap = preset?.data?.meta?.dsp_map || {};
     const blockModel = block["@model"];
     const dspCost = dspMap[blockModel] || 0;
 
@@ -112,7 +142,7 @@ const BlockParameters = ({ block, blockKey, color, activeSnapshot, preset, onClo
             <div className="grid grid-cols-2 md:grid-cols-3 lg:grid-cols-4 gap-4">
                 {params.length > 0 ? (
                     params.map(([pKey, pVal]) => {
-                        const currentVal = getParamValue(pKey, pVal);
+                        const currentVal = currentValues[pKey];
                         const isControlled = isSnapshotControlled(pKey);
                         return (
                             <div key={pKey} className={`bg-white dark:bg-background-dark border rounded-lg p-2.5 flex flex-col gap-1 transition-all ${isControlled ? 'border-blue-500/50 shadow-[0_0_10px_rgba(59,130,246,0.1)]' : 'border-slate-300 dark:border-border-dark hover:border-slate-400 dark:hover:border-[#3b4f54]'}`}>
@@ -127,11 +157,11 @@ const BlockParameters = ({ block, blockKey, color, activeSnapshot, preset, onClo
                                 </div>
                                 <div className="flex items-center justify-between">
                                     <span className={`font-mono text-xs ${isControlled ? 'text-blue-500 font-black' : 'text-slate-900 dark:text-white'}`}>
-                                        {typeof currentVal === 'number' ? currentVal.toFixed(2) : String(currentVal)}
+                                        {display[pKey] ?? (typeof currentVal === 'number' ? currentVal.toFixed(2) : String(currentVal))}
                                     </span>
                                     {typeof currentVal === 'number' && (
                                         <div className="w-12 h-1 bg-slate-300 dark:bg-border-dark rounded-full overflow-hidden">
-                                            <div className="h-full bg-primary" style={{ width: `${Math.min(100, Math.max(0, currentVal * 100))}%`, backgroundColor: isControlled ? '#3b82f6' : color }}></div>
+                                            <div className="h-full bg-primary" style={{ width: `${fillPercent(pKey, currentVal)}%`, backgroundColor: isControlled ? '#3b82f6' : color }}></div>
                                         </div>
                                     )}
                                 </div>
@@ -146,7 +176,7 @@ const BlockParameters = ({ block, blockKey, color, activeSnapshot, preset, onClo
     );
 };
 
-const SignalChain = ({ dspBlocks, activeSnapshot, preset }) => {
+const SignalChain = ({ dspBlocks, activeSnapshot, preset, dspMap = {} }) => {
     const [expandedBlock, setExpandedBlock] = useState(null);
 
     const toggleBlock = (key) => {
@@ -154,7 +184,6 @@ const SignalChain = ({ dspBlocks, activeSnapshot, preset }) => {
     };
 
     // Total DSP Sum
-    const dspMap = preset?.data?.meta?.dsp_map || {};
     const totalDSP = dspBlocks.reduce((sum, [_, b]) => sum + (dspMap[b["@model"]] || 0), 0);
 
     return (
@@ -267,7 +296,7 @@ const SignalChain = ({ dspBlocks, activeSnapshot, preset }) => {
                                     {/* Inline Expanded Area (Vertical/Mobile) */}
                                     <div className={`xl:hidden w-80 relative z-10 overflow-hidden transition-all duration-300 ease-in-out ${isExpanded ? 'max-h-[800px] opacity-100 mt-4 border border-slate-300 dark:border-border-dark bg-white dark:bg-[#0b1011] rounded-xl shadow-2xl' : 'max-h-0 opacity-0'}`}>
                                         {isExpanded && (
-                                            <BlockParameters block={block} blockKey={key} color={color} activeSnapshot={activeSnapshot} preset={preset} onClose={() => setExpandedBlock(null)} rigTotal={totalDSP} />
+                                            <BlockParameters block={block} blockKey={key} color={color} activeSnapshot={activeSnapshot} preset={preset} dspMap={dspMap} onClose={() => setExpandedBlock(null)} rigTotal={totalDSP} />
                                         )}
                                     </div>
                                 </div>
@@ -288,7 +317,7 @@ const SignalChain = ({ dspBlocks, activeSnapshot, preset }) => {
                 {expandedBlock && (() => {
                     const blk = dspBlocks.find(([k]) => k === expandedBlock)[1];
                     const clr = getBlockColor(blk);
-                    return <BlockParameters block={blk} blockKey={expandedBlock} color={clr} activeSnapshot={activeSnapshot} preset={preset} onClose={() => setExpandedBlock(null)} rigTotal={totalDSP} />;
+                    return <BlockParameters block={blk} blockKey={expandedBlock} color={clr} activeSnapshot={activeSnapshot} preset={preset} dspMap={dspMap} onClose={() => setExpandedBlock(null)} rigTotal={totalDSP} />;
                 })()}
             </div>
         </div>
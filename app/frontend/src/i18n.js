@@ -24,7 +24,21 @@ const translations = {
             signalChainStatus: "Signal Chain Status",
             generateBtn: "Build this rig",
             exportBtn: "Export .hlx file",
+            regenerateBtn: "Regenerate",
             currentChat: "Current Chat",
+            cancel: "Stop",
+            progress: {
+                designing: "Designing the rig...",
+                building: "Building the preset...",
+                mapped: "Blocks mapped",
+                retrying: "Gemini is busy, retrying"
+            },
+            validation: {
+                title: "Preset check",
+                ok: "No problem found",
+                errors: "errors",
+                warnings: "warnings"
+            },
             errors: {
                 ia: "AI might make mistakes. Always check output levels before playing."
             },
@@ -34,6 +48,44 @@ const translations = {
             deleteConfirmOk: "Delete",
             deleteConfirmCancel: "Cancel"
         },
+        errors: {
+            quota_exhausted: "Your Gemini API quota is exhausted. Try again tomorrow or use another key.",
+            rate_limited: "Too many requests to Gemini. Wait a moment and try again.",
+            invalid_key: "The Gemini API key is invalid. Check it in the settings.",
+            model_not_found: "The selected model is not available. Choose another one in the settings.",
+            safety_block: "Gemini blocked this request for safety reasons. Try rephrasing it.",
+            unavailable: "Gemini is temporarily unavailable. Try again in a few moments.",
+            provider_error: "The AI provider returned an error."
+        },
+        modelPicker: {
+            title: "Helix models",
+            search: "Search a model (e.g. plexi, tape echo, 808)",
+            allCategories: "All categories",
+            empty: "No model found.",
+            browse: "Browse Helix models",
+            categories: {
+                amp: "Amp", cab: "Cab", distortion: "Distortion", dynamics: "Dynamics", eq: "EQ",
+                modulation: "Modulation", delay: "Delay", reverb: "Reverb", pitch_synth: "Pitch/Synth",
+                filter: "Filter", wah: "Wah", volume: "Volume/Pan", routing: "Routing", other: "Other"
+            }
+        },
+        setlist: {
+            title: "Match setlist volume",
+            description: "Balances the output level of several presets on a reference preset. Preview the trims, then apply them to rewrite the files.",
+            open: "Match a setlist...",
+            choose: "Choose presets",
+            noFiles: "No preset selected",
+            reference: "Reference",
+            empty: "Choose at least two presets, then preview the trims.",
+            preset: "Preset",
+            level: "Estimated level",
+            trim: "Trim",
+            isReference: "reference",
+            preview: "Preview",
+            apply: "Apply to files",
+            confirm: "Rewrite {count} preset files with the new output levels?",
+            done: "Presets updated."
+        },
         exportModal: {
             title: "Preset Generated Successfully",
             description: "The configuration file has been compiled and exported. You can now import it into your Helix processor.",
@@ -48,11 +100,32 @@ const translations = {
             aiSection: "Artificial Intelligence",
             provider: "AI Provider",
             model: "LLM Model",
+            fallbackModels: "Fallback models",
+            fallbackModelsHint: "Comma-separated, tried in order when the main model is overloaded, out of quota or retired.",
             apiKey: "API Key",
             testConn: "Test Connection",
             keyHint: "Your key is stored locally and never shared.",
+            cacheEnabled: "Reuse identical AI responses",
+            cacheEnabledHint: "Re-running the same request with the same model is answered from a local cache (7 days, 50 MB).",
+            cacheClear: "Clear cache",
+            cacheCleared: "Response cache cleared.",
+            gainStaging: "Balance output levels",
+            gainStagingHint: "Trims the output block so every snapshot of a generated preset plays at about the same volume.",
+            targetLevel: "Target",
+            usageSection: "Usage & spend",
+            usageEmpty: "No AI request recorded yet.",
+            usageToday: "Latest day",
+            usageTotal: "Total",
+            usageRequests: "Requests",
+            usageDay: "Day",
+            usageTokensIn: "Prompt tokens",
+            usageTokensOut: "Response tokens",
+            usageCost: "Est. cost (USD)",
+            usageUnpriced: "Some models have no known price and are not counted.",
             exportSection: "Export target folder",
             browse: "Browse",
+            presetAuthor: "Preset author",
+            presetAuthorHint: "Written with the prompt and the AI model in the metadata of generated presets.",
             folderHint: "Generated files will be automatically saved here.",
             overwrite: "Overwrite existing files",
             overwriteHint: "Replace without asking if a file exists.",
@@ -70,6 +143,8 @@ const translations = {
             deleteNoConfirmHint: "Skip the confirmation popup when deleting a chat.",
             defaultExpPedal: "Default Expression Pedal",
             defaultExpHint: "The assigned controller for Wah, Volume, and Pitch Wham by default.",
+            firmwareVersion: "Helix Firmware",
+            firmwareHint: "Only models available on this firmware will be used.",
             expOptions: {
                 none: "Nothing",
                 exp1: "Exp 1",
@@ -119,7 +194,21 @@ const translations = {
             signalChainStatus: "Signal Chain Status",
             generateBtn: "Générer ce rig",
             exportBtn: "Exporter le fichier .hlx",
+            regenerateBtn: "Régénérer",
             currentChat: "Chat en cours",
+            cancel: "Arrêter",
+            progress: {
+                designing: "Conception du rig...",
+                building: "Construction du preset...",
+                mapped: "Blocs placés",
+                retrying: "Gemini est surchargé, nouvel essai"
+            },
+            validation: {
+                title: "Vérification du preset",
+                ok: "Aucun problème détecté",
+                errors: "erreurs",
+                warnings: "avertissements"
+            },
             errors: {
                 ia: "L'IA peut faire des erreurs. Vérifiez toujours vos niveaux de sortie avant de jouer."
             },
@@ -129,6 +218,44 @@ const translations = {
             deleteConfirmOk: "Supprimer",
             deleteConfirmCancel: "Annuler"
         },
+        errors: {
+            quota_exhausted: "Le quota de votre clé API Gemini est épuisé. Réessayez demain ou utilisez une autre clé.",
+            rate_limited: "Trop de requêtes vers Gemini. Patientez un instant puis réessayez.",
+            invalid_key: "La clé API Gemini est invalide. Vérifiez-la dans les paramètres.",
+            model_not_found: "Le modèle sélectionné n'est pas disponible. Choisissez-en un autre dans les paramètres.",
+            safety_block: "Gemini a bloqué cette requête pour des raisons de sécurité. Essayez de la reformuler.",
+            unavailable: "Gemini est temporairement indisponible. Réessayez dans quelques instants.",
+            provider_error: "Le fournisseur d'IA a renvoyé une erreur."
+        },
+        modelPicker: {
+            title: "Modèles Helix",
+            search: "Chercher un modèle (ex. plexi, tape echo, 808)",
+            allCategories: "Toutes les catégories",
+            empty: "Aucun modèle trouvé.",
+            browse: "Parcourir les modèles Helix",
+            categories: {
+                amp: "Ampli", cab: "Baffle", distortion: "Distorsion", dynamics: "Dynamique", eq: "EQ",
+                modulation: "Modulation", delay: "Delay", reverb: "Réverbération", pitch_synth: "Pitch/Synth",
+                filter: "Filtre", wah: "Wah", volume: "Volume/Pan", routing: "Routage", other: "Autre"
+            }
+        },
+        setlist: {
+            title: "Harmoniser le volume d'une setlist",
+            description: "Équilibre le niveau de sortie de plusieurs presets sur un preset de référence. Prévisualisez les corrections, puis appliquez-les pour réécrire les fichiers.",
+            open: "Harmoniser une setlist...",
+            choose: "Choisir des presets",
+            noFiles: "Aucun preset sélectionné",
+            reference: "Référence",
+            empty: "Choisissez au moins deux presets, puis prévisualisez les corrections.",
+            preset: "Preset",
+            level: "Niveau estimé",
+            trim: "Correction",
+            isReference: "référence",
+            preview: "Prévisualiser",
+            apply: "Appliquer aux fichiers",
+            confirm: "Réécrire {count} fichiers de presets avec les nouveaux niveaux de sortie ?",
+            done: "Presets mis à jour."
+        },
         exportModal: {
             title: "Preset généré avec succès",
             description: "Le fichier de configuration a été compilé et exporté. Vous pouvez maintenant l'importer dans votre pédalier Helix.",
@@ -143,11 +270,32 @@ const translations = {
             aiSection: "Intelligence Artificielle",
             provider: "Fournisseur d'IA",
             model: "Modèle LLM",
+            fallbackModels: "Modèles de secours",
+            fallbackModelsHint: "Séparés par des virgules, essayés dans l'ordre si le modèle principal est surchargé, sans quota ou retiré.",
             apiKey: "Clé API",
             testConn: "Tester la connexion",
             keyHint: "Votre clé est stockée localement et n'est jamais partagée.",
+            cacheEnabled: "Réutiliser les réponses IA identiques",
+            cacheEnabledHint: "Relancer la même requête avec le même modèle utilise un cache local (7 jours, 50 Mo).",
+            cacheClear: "Vider le cache",
+            cacheCleared: "Cache des réponses vidé.",
+            gainStaging: "Équilibrer les niveaux de sortie",
+            gainStagingHint: "Ajuste le bloc de sortie pour que chaque snapshot d'un preset généré joue à peu près au même volume.",
+            targetLevel: "Cible",
+            usageSection: "Consommation et coûts",
+            usageEmpty: "Aucune requête IA enregistrée pour l'instant.",
+            usageToday: "Dernier jour",
+            usageTotal: "Total",
+            usageRequests: "Requêtes",
+            usageDay: "Jour",
+            usageTokensIn: "Tokens envoyés",
+            usageTokensOut: "Tokens reçus",
+            usageCost: "Coût estimé (USD)",
+            usageUnpriced: "Certains modèles n'ont pas de prix connu et ne sont pas comptés.",
             exportSection: "Dossier d'exportation cible",
             browse: "Parcourir",
+            presetAuthor: "Auteur des presets",
+            presetAuthorHint: "Enregistré avec la demande et le modèle d'IA dans les métadonnées des presets générés.",
             folderHint: "Les fichiers générés seront automatiquement sauvegardés ici.",
             overwrite: "Écraser les fichiers existants",
             overwriteHint: "Remplacer sans demander si un fichier existe.",
@@ -165,6 +313,8 @@ const translations = {
             deleteNoConfirmHint: "Passer la fenêtre de confirmation lors de la suppression d'un chat.",
             defaultExpPedal: "Pédale d'Expression par Défaut",
             defaultExpHint: "Le contrôleur assigné par défaut pour les blocs Wah, Volume et Pitch Wham.",
+            firmwareVersion: "Firmware Helix",
+            firmwareHint: "Seuls les modèles disponibles sur ce firmware seront utilisés.",
             expOptions: {
                 none: "Rien",
                 exp1: "Exp 1",
@@ -193,6 +343,15 @@ const translations = {
     }
 };
 
+// errorMessage turns a backend error (plain string or typed provider error) into a localized message
+export const errorMessage = (err, t) => {
+    if (err && typeof err === 'object' && err.kind) {
+        const msg = t(`errors.${err.kind}`);
+        return err.retry_after ? `${msg} (${err.retry_after}s)` : msg;
+    }
+    return String(err);
+};
+
 export const useI18n = () => {
     // Basic implementation: check localStorage or default to 'en'
     const [lang, setLang] = useState(localStorage.getItem('helAIx_lang') || 'en');
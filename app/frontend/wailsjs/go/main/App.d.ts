@@ -3,17 +3,43 @@
 import {gemini} from '../models';
 import {helix} from '../models';
 import {config} from '../models';
+import {usage} from '../models';
+import {main} from '../models';
 
-export function GxChatPresetEngineer(arg1:gemini.RigDescription,arg2:string,arg3:Array<gemini.ChatMessage>):Promise<helix.Preset>;
+export function GxCancelRequest(arg1:string):Promise<boolean>;
 
-export function GxChatSoundEngineer(arg1:Array<gemini.ChatMessage>):Promise<gemini.RigDescription>;
+export function GxChatPresetEngineer(arg1:main.RequestOptions,arg2:gemini.RigDescription,arg3:string,arg4:Array<gemini.ChatMessage>):Promise<gemini.PresetResult>;
+
+export function GxChatSoundEngineer(arg1:main.RequestOptions,arg2:Array<gemini.ChatMessage>):Promise<gemini.RigDescription>;
+
+export function GxCheckCompatibility(arg1:helix.Preset):Promise<Array<helix.CompatibilityIssue>>;
+
+export function GxClearResponseCache():Promise<void>;
+
+export function GxDeleteBlock(arg1:helix.Preset,arg2:helix.BlockRef):Promise<helix.EditResult>;
+
+export function GxFormatParams(arg1:string,arg2:Record<string, any>):Promise<Record<string, string>>;
 
 export function GxGetConfig():Promise<config.AppConfig>;
 
 export function GxGetDefaultOutputPath():Promise<string>;
 
+export function GxGetModelParams(arg1:string):Promise<Array<helix.ParamInfo>>;
+
+export function GxGetSupportedFirmware():Promise<Array<string>>;
+
+export function GxGetUsage():Promise<usage.Summary>;
+
+export function GxImportHLXFolder(arg1:string):Promise<helix.ImportReport>;
+
 export function GxListModels(arg1:string,arg2:string):Promise<Array<string>>;
 
+export function GxMatchSetlist(arg1:Array<string>,arg2:string,arg3:boolean):Promise<helix.SetlistReport>;
+
+export function GxMoveBlock(arg1:helix.Preset,arg2:helix.BlockRef,arg3:number,arg4:number):Promise<helix.EditResult>;
+
+export function GxNormalizeLevels(arg1:helix.Preset):Promise<helix.LevelResult>;
+
 export function GxOpenFolderOfFile(arg1:string):Promise<void>;
 
 export function GxOpenPath(arg1:string):Promise<void>;
@@ -22,6 +48,18 @@ export function GxSaveConfig(arg1:config.AppConfig):Promise<string>;
 
 export function GxSaveFile(arg1:helix.Preset,arg2:string):Promise<string>;
 
+export function GxSearchCatalog(arg1:helix.SearchQuery):Promise<Array<helix.SearchResult>>;
+
 export function GxSelectFolder(arg1:string):Promise<string>;
 
+export function GxSelectPresetFiles(arg1:string):Promise<Array<string>>;
+
+export function GxSetBlockParam(arg1:helix.Preset,arg2:helix.BlockRef,arg3:string,arg4:any,arg5:number):Promise<helix.EditResult>;
+
+export function GxSetBlockSnapshotBypass(arg1:helix.Preset,arg2:helix.BlockRef,arg3:number,arg4:boolean):Promise<helix.EditResult>;
+
+export function GxSwapBlockModel(arg1:helix.Preset,arg2:helix.BlockRef,arg3:string):Promise<helix.EditResult>;
+
 export function GxTestConnection(arg1:string,arg2:string):Promise<string>;
+
+export function GxValidatePreset(arg1:helix.Preset):Promise<Array<helix.Diagnostic>>;
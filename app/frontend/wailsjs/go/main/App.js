@@ -2,12 +2,32 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function GxChatPresetEngineer(arg1, arg2, arg3) {
-  return window['go']['main']['App']['GxChatPresetEngineer'](arg1, arg2, arg3);
+export function GxCancelRequest(arg1) {
+  return window['go']['main']['App']['GxCancelRequest'](arg1);
 }
 
-export function GxChatSoundEngineer(arg1) {
-  return window['go']['main']['App']['GxChatSoundEngineer'](arg1);
+export function GxChatPresetEngineer(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GxChatPresetEngineer'](arg1, arg2, arg3, arg4);
+}
+
+export function GxChatSoundEngineer(arg1, arg2) {
+  return window['go']['main']['App']['GxChatSoundEngineer'](arg1, arg2);
+}
+
+export function GxCheckCompatibility(arg1) {
+  return window['go']['main']['App']['GxCheckCompatibility'](arg1);
+}
+
+export function GxClearResponseCache() {
+  return window['go']['main']['App']['GxClearResponseCache']();
+}
+
+export function GxDeleteBlock(arg1, arg2) {
+  return window['go']['main']['App']['GxDeleteBlock'](arg1, arg2);
+}
+
+export function GxFormatParams(arg1, arg2) {
+  return window['go']['main']['App']['GxFormatParams'](arg1, arg2);
 }
 
 export function GxGetConfig() {
@@ -18,10 +38,38 @@ export function GxGetDefaultOutputPath() {
   return window['go']['main']['App']['GxGetDefaultOutputPath']();
 }
 
+export function GxGetModelParams(arg1) {
+  return window['go']['main']['App']['GxGetModelParams'](arg1);
+}
+
+export function GxGetSupportedFirmware() {
+  return window['go']['main']['App']['GxGetSupportedFirmware']();
+}
+
+export function GxGetUsage() {
+  return window['go']['main']['App']['GxGetUsage']();
+}
+
+export function GxImportHLXFolder(arg1) {
+  return window['go']['main']['App']['GxImportHLXFolder'](arg1);
+}
+
 export function GxListModels(arg1, arg2) {
   return window['go']['main']['App']['GxListModels'](arg1, arg2);
 }
 
+export function GxMatchSetlist(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GxMatchSetlist'](arg1, arg2, arg3);
+}
+
+export function GxMoveBlock(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GxMoveBlock'](arg1, arg2, arg3, arg4);
+}
+
+export function GxNormalizeLevels(arg1) {
+  return window['go']['main']['App']['GxNormalizeLevels'](arg1);
+}
+
 export function GxOpenFolderOfFile(arg1) {
   return window['go']['main']['App']['GxOpenFolderOfFile'](arg1);
 }
@@ -38,10 +86,34 @@ export function GxSaveFile(arg1, arg2) {
   return window['go']['main']['App']['GxSaveFile'](arg1, arg2);
 }
 
+export function GxSearchCatalog(arg1) {
+  return window['go']['main']['App']['GxSearchCatalog'](arg1);
+}
+
 export function GxSelectFolder(arg1) {
   return window['go']['main']['App']['GxSelectFolder'](arg1);
 }
 
+export function GxSelectPresetFiles(arg1) {
+  return window['go']['main']['App']['GxSelectPresetFiles'](arg1);
+}
+
+export function GxSetBlockParam(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GxSetBlockParam'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GxSetBlockSnapshotBypass(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GxSetBlockSnapshotBypass'](arg1, arg2, arg3, arg4);
+}
+
+export function GxSwapBlockModel(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GxSwapBlockModel'](arg1, arg2, arg3);
+}
+
 export function GxTestConnection(arg1, arg2) {
   return window['go']['main']['App']['GxTestConnection'](arg1, arg2);
 }
+
+export function GxValidatePreset(arg1) {
+  return window['go']['main']['App']['GxValidatePreset'](arg1);
+}
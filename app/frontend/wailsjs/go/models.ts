@@ -4,6 +4,7 @@ export namespace config {
 	    api_key: string;
 	    provider: string;
 	    model: string;
+	    fallback_models: string[];
 	    output_path: string;
 	    hardware_target: string;
 	    delete_no_confirm: boolean;
@@ -11,6 +12,14 @@ export namespace config {
 	    default_exp_pedal: number;
 	    variax_enabled: boolean;
 	    variax_hardware_model: string;
+	    firmware_version: string;
+	    model_prices?: Record<string, usage.Price>;
+	    cache_enabled: boolean;
+	    cache_ttl_hours: number;
+	    cache_max_mb: number;
+	    gain_staging: boolean;
+	    target_level_db: number;
+	    preset_author: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new AppConfig(source);
@@ -21,6 +30,7 @@ export namespace config {
 	        this.api_key = source["api_key"];
 	        this.provider = source["provider"];
 	        this.model = source["model"];
+	        this.fallback_models = source["fallback_models"];
 	        this.output_path = source["output_path"];
 	        this.hardware_target = source["hardware_target"];
 	        this.delete_no_confirm = source["delete_no_confirm"];
@@ -28,7 +38,33 @@ export namespace config {
 	        this.default_exp_pedal = source["default_exp_pedal"];
 	        this.variax_enabled = source["variax_enabled"];
 	        this.variax_hardware_model = source["variax_hardware_model"];
+	        this.firmware_version = source["firmware_version"];
+	        this.model_prices = this.convertValues(source["model_prices"], usage.Price, true);
+	        this.cache_enabled = source["cache_enabled"];
+	        this.cache_ttl_hours = source["cache_ttl_hours"];
+	        this.cache_max_mb = source["cache_max_mb"];
+	        this.gain_staging = source["gain_staging"];
+	        this.target_level_db = source["target_level_db"];
+	        this.preset_author = source["preset_author"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -49,11 +85,60 @@ export namespace gemini {
 	        this.content = source["content"];
 	    }
 	}
+	export class PresetResult {
+	    preset: Record<string, any>;
+	    view: PresetView;
+	
+	    static createFrom(source: any = {}) {
+	        return new PresetResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.preset = source["preset"];
+	        this.view = this.convertValues(source["view"], PresetView);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PresetView {
+	    dsp_map: Record<string, number>;
+	    variax_type: string;
+	    generated_by: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PresetView(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dsp_map = source["dsp_map"];
+	        this.variax_type = source["variax_type"];
+	        this.generated_by = source["generated_by"];
+	    }
+	}
 	export class RigComponent {
 	    type: string;
 	    name: string;
 	    description: string;
 	    settings: string;
+	    note_division?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new RigComponent(source);
@@ -65,14 +150,68 @@ export namespace gemini {
 	        this.name = source["name"];
 	        this.description = source["description"];
 	        this.settings = source["settings"];
+	        this.note_division = source["note_division"];
 	    }
 	}
+	export class RigDescription {
+	    suggested_name: string;
+	    explanation: string;
+	    guitar_model: string;
+	    tuning: string;
+	    chain: RigComponent[];
+	    snapshots?: Snapshot[];
+	    tempo?: number;
+	    input?: helix.InputConfig;
+	    genre?: string;
+	    tags?: string[];
+	    prompt?: string;
+	    generated_by?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RigDescription(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.suggested_name = source["suggested_name"];
+	        this.explanation = source["explanation"];
+	        this.guitar_model = source["guitar_model"];
+	        this.tuning = source["tuning"];
+	        this.chain = this.convertValues(source["chain"], RigComponent);
+	        this.snapshots = this.convertValues(source["snapshots"], Snapshot);
+	        this.tempo = source["tempo"];
+	        this.input = this.convertValues(source["input"], helix.InputConfig);
+	        this.genre = source["genre"];
+	        this.tags = source["tags"];
+	        this.prompt = source["prompt"];
+	        this.generated_by = source["generated_by"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Snapshot {
 	    name: string;
 	    active_blocks: string[];
 	    guitar_model?: string;
 	    tuning?: string;
 	    params?: Record<string, any>;
+	    tempo?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Snapshot(source);
@@ -85,28 +224,364 @@ export namespace gemini {
 	        this.guitar_model = source["guitar_model"];
 	        this.tuning = source["tuning"];
 	        this.params = source["params"];
+	        this.tempo = source["tempo"];
 	    }
 	}
-	export class RigDescription {
-	    suggested_name: string;
-	    explanation: string;
-	    guitar_model: string;
-	    tuning: string;
-	    chain: RigComponent[];
-	    snapshots?: Snapshot[];
+
+}
+
+export namespace helix {
+	
+	export class BlockRef {
+	    path: number;
+	    key: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new RigDescription(source);
+	        return new BlockRef(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.suggested_name = source["suggested_name"];
-	        this.explanation = source["explanation"];
-	        this.guitar_model = source["guitar_model"];
-	        this.tuning = source["tuning"];
-	        this.chain = this.convertValues(source["chain"], RigComponent);
-	        this.snapshots = this.convertValues(source["snapshots"], Snapshot);
+	        this.path = source["path"];
+	        this.key = source["key"];
+	    }
+	}
+	export class CompatibilityIssue {
+	    dsp: string;
+	    block: string;
+	    model: string;
+	    replacement?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompatibilityIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dsp = source["dsp"];
+	        this.block = source["block"];
+	        this.model = source["model"];
+	        this.replacement = source["replacement"];
+	    }
+	}
+	export class Diagnostic {
+	    severity: string;
+	    code: string;
+	    location: string;
+	    message: string;
+	    fix?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Diagnostic(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.severity = source["severity"];
+	        this.code = source["code"];
+	        this.location = source["location"];
+	        this.message = source["message"];
+	        this.fix = source["fix"];
+	    }
+	}
+	export class EditResult {
+	    preset: Record<string, any>;
+	    dsp: number[];
+	    diagnostics: Diagnostic[];
+	    dropped?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new EditResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.preset = source["preset"];
+	        this.dsp = source["dsp"];
+	        this.diagnostics = this.convertValues(source["diagnostics"], Diagnostic);
+	        this.dropped = source["dropped"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportReport {
+	    files_scanned: number;
+	    files_failed?: string[];
+	    learned: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files_scanned = source["files_scanned"];
+	        this.files_failed = source["files_failed"];
+	        this.learned = source["learned"];
+	    }
+	}
+	export class InputConfig {
+	    noise_gate?: boolean;
+	    threshold?: number;
+	    decay?: number;
+	    impedance?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new InputConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.noise_gate = source["noise_gate"];
+	        this.threshold = source["threshold"];
+	        this.decay = source["decay"];
+	        this.impedance = source["impedance"];
+	    }
+	}
+	export class LevelResult {
+	    preset: Record<string, any>;
+	    snapshots: SnapshotLevel[];
+	
+	    static createFrom(source: any = {}) {
+	        return new LevelResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.preset = source["preset"];
+	        this.snapshots = this.convertValues(source["snapshots"], SnapshotLevel);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ParamInfo {
+	    name: string;
+	    type: string;
+	    min: number;
+	    max: number;
+	    unit: string;
+	    default: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ParamInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.min = source["min"];
+	        this.max = source["max"];
+	        this.unit = source["unit"];
+	        this.default = source["default"];
+	    }
+	}
+	export class SearchQuery {
+	    text: string;
+	    category: string;
+	    max_dsp: number;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.text = source["text"];
+	        this.category = source["category"];
+	        this.max_dsp = source["max_dsp"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class SearchResult {
+	    internal_name: string;
+	    name: string;
+	    based_on: string;
+	    category: string;
+	    dsp_mono: number;
+	    dsp_stereo: number;
+	    score: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.internal_name = source["internal_name"];
+	        this.name = source["name"];
+	        this.based_on = source["based_on"];
+	        this.category = source["category"];
+	        this.dsp_mono = source["dsp_mono"];
+	        this.dsp_stereo = source["dsp_stereo"];
+	        this.score = source["score"];
+	    }
+	}
+	export class SetlistEntry {
+	    file: string;
+	    name: string;
+	    estimated_db: number;
+	    trim_db: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SetlistEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.name = source["name"];
+	        this.estimated_db = source["estimated_db"];
+	        this.trim_db = source["trim_db"];
+	        this.error = source["error"];
+	    }
+	}
+	export class SetlistReport {
+	    reference: string;
+	    reference_db: number;
+	    presets: SetlistEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SetlistReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.reference = source["reference"];
+	        this.reference_db = source["reference_db"];
+	        this.presets = this.convertValues(source["presets"], SetlistEntry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SnapshotLevel {
+	    snapshot: number;
+	    name: string;
+	    estimated_db: number;
+	    trim_db: number;
+	    output_db: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotLevel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.snapshot = source["snapshot"];
+	        this.name = source["name"];
+	        this.estimated_db = source["estimated_db"];
+	        this.trim_db = source["trim_db"];
+	        this.output_db = source["output_db"];
+	    }
+	}
+
+}
+
+export namespace main {
+	
+	export class RequestOptions {
+	    request_id: string;
+	    chat_id: string;
+	    bypass_cache: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RequestOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.request_id = source["request_id"];
+	        this.chat_id = source["chat_id"];
+	        this.bypass_cache = source["bypass_cache"];
+	    }
+	}
+
+}
+
+export namespace usage {
+	
+	export class Price {
+	    input_per_million: number;
+	    output_per_million: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Price(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.input_per_million = source["input_per_million"];
+	        this.output_per_million = source["output_per_million"];
+	    }
+	}
+	export class Summary {
+	    total: Totals;
+	    by_chat: Record<string, Totals>;
+	    by_day: Record<string, Totals>;
+	    by_model: Record<string, Totals>;
+	    days: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Summary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = this.convertValues(source["total"], Totals);
+	        this.by_chat = this.convertValues(source["by_chat"], Totals, true);
+	        this.by_day = this.convertValues(source["by_day"], Totals, true);
+	        this.by_model = this.convertValues(source["by_model"], Totals, true);
+	        this.days = source["days"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -127,6 +602,26 @@ export namespace gemini {
 		    return a;
 		}
 	}
+	export class Totals {
+	    requests: number;
+	    prompt_tokens: number;
+	    response_tokens: number;
+	    cost: number;
+	    priced: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Totals(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.requests = source["requests"];
+	        this.prompt_tokens = source["prompt_tokens"];
+	        this.response_tokens = source["response_tokens"];
+	        this.cost = source["cost"];
+	        this.priced = source["priced"];
+	    }
+	}
 
 }
 
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"HelAIx/pkg/usage"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -8,16 +9,25 @@ import (
 )
 
 type AppConfig struct {
-	ApiKey              string `json:"api_key"`
-	Provider            string `json:"provider"` // "Google" = Gemini API (ai.google.dev), "Vertex" = Vertex AI
-	Model               string `json:"model"`    // e.g., "gemini-2.5-flash", "gemini-3-flash-preview"
-	OutputPath          string `json:"output_path"`
-	HardwareTarget      string `json:"hardware_target"`
-	DeleteNoConfirm     bool   `json:"delete_no_confirm"`
-	IncrementalSave     bool   `json:"incremental_save"`
-	DefaultExpPedal     int    `json:"default_exp_pedal"`     // 0 = None, 1 = Exp 1, 2 = Exp 2, 3 = Exp 3
-	VariaxEnabled       bool   `json:"variax_enabled"`        // Whether to control Variax
-	VariaxHardwareModel string `json:"variax_hardware_model"` // JTV, Standard, Shuriken
+	ApiKey              string                 `json:"api_key"`
+	Provider            string                 `json:"provider"`        // "Google" = Gemini API (ai.google.dev), "Vertex" = Vertex AI
+	Model               string                 `json:"model"`           // e.g., "gemini-2.5-flash", "gemini-3-flash-preview"
+	FallbackModels      []string               `json:"fallback_models"` // Tried in order when Model is overloaded, out of quota or retired
+	OutputPath          string                 `json:"output_path"`
+	HardwareTarget      string                 `json:"hardware_target"`
+	DeleteNoConfirm     bool                   `json:"delete_no_confirm"`
+	IncrementalSave     bool                   `json:"incremental_save"`
+	DefaultExpPedal     int                    `json:"default_exp_pedal"`      // 0 = None, 1 = Exp 1, 2 = Exp 2, 3 = Exp 3
+	VariaxEnabled       bool                   `json:"variax_enabled"`         // Whether to control Variax
+	VariaxHardwareModel string                 `json:"variax_hardware_model"`  // JTV, Standard, Shuriken
+	FirmwareVersion     string                 `json:"firmware_version"`       // Target Helix firmware, e.g. "3.80"
+	ModelPrices         map[string]usage.Price `json:"model_prices,omitempty"` // USD per million tokens by model prefix, overrides the built-in table
+	CacheEnabled        bool                   `json:"cache_enabled"`          // Reuse identical agent responses from the on-disk cache
+	CacheTTLHours       int                    `json:"cache_ttl_hours"`        // 0 = never expires
+	CacheMaxMB          int                    `json:"cache_max_mb"`           // 0 = unlimited
+	GainStaging         bool                   `json:"gain_staging"`           // Balance snapshot output levels of generated presets
+	TargetLevelDB       float64                `json:"target_level_db"`        // Estimated level presets are balanced to, 0 = template level
+	PresetAuthor        string                 `json:"preset_author"`          // Author written in the metadata of generated presets
 }
 
 type Manager struct {
@@ -42,11 +52,17 @@ func NewManager() *Manager {
 		config: AppConfig{
 			Provider:            "Google",           // Gemini API (not Vertex AI)
 			Model:               "gemini-2.5-flash", // Updated to current stable model
+			FallbackModels:      []string{},
 			OutputPath:          defaultOutPath,
 			HardwareTarget:      "Helix Floor",
 			DefaultExpPedal:     1, // Default to Exp 1
 			VariaxEnabled:       false,
 			VariaxHardwareModel: "Standard",
+			FirmwareVersion:     "3.80",
+			CacheEnabled:        true,
+			CacheTTLHours:       24 * 7,
+			CacheMaxMB:          50,
+			GainStaging:         true,
 		},
 	}
 	m.Load()
@@ -88,3 +104,8 @@ func (m *Manager) Get() AppConfig {
 	defer m.mu.RUnlock()
 	return m.config
 }
+
+// Dir returns the directory holding the settings and other user data files
+func (m *Manager) Dir() string {
+	return filepath.Dir(m.configPath)
+}
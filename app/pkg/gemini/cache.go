@@ -0,0 +1,152 @@
+package gemini
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// cacheProvider is part of every cache key, so responses from other providers never collide
+const cacheProvider = "gemini"
+
+// ResponseCache stores raw agent responses on disk, addressed by the hash of the request.
+// Re-running the same model on the same prompt and history returns the stored text without a provider call.
+type ResponseCache struct {
+	dir      string
+	ttl      time.Duration // 0 = never expires
+	maxBytes int64         // 0 = unlimited
+
+	mu sync.Mutex
+}
+
+// cacheEntry is the on-disk format of a cached response
+type cacheEntry struct {
+	Model   string    `json:"model"` // Model that produced the text (may be a fallback)
+	Text    string    `json:"text"`
+	Created time.Time `json:"created"`
+}
+
+// NewResponseCache returns a cache stored in dir
+func NewResponseCache(dir string, ttl time.Duration, maxBytes int64) *ResponseCache {
+	return &ResponseCache{dir: dir, ttl: ttl, maxBytes: maxBytes}
+}
+
+// CacheKey hashes everything that determines the response: provider, model, prompt/history and generation config
+func CacheKey(model string, contents []*genai.Content, config *genai.GenerateContentConfig) string {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	enc.Encode(cacheProvider)
+	enc.Encode(strings.TrimPrefix(model, "models/"))
+	enc.Encode(contents)
+	enc.Encode(config)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path returns the file of a key, sharded by its first two characters
+func (rc *ResponseCache) path(key string) string {
+	return filepath.Join(rc.dir, key[:2], key+".json")
+}
+
+// Configure updates the expiry and size limit, so one cache can follow the settings
+func (rc *ResponseCache) Configure(ttl time.Duration, maxBytes int64) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.ttl = ttl
+	rc.maxBytes = maxBytes
+}
+
+// Get returns the cached text and the model that produced it. Expired entries are deleted.
+func (rc *ResponseCache) Get(key string) (text string, model string, ok bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	data, err := os.ReadFile(rc.path(key))
+	if err != nil {
+		return "", "", false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil || e.Text == "" {
+		os.Remove(rc.path(key))
+		return "", "", false
+	}
+	if rc.ttl > 0 && time.Since(e.Created) > rc.ttl {
+		os.Remove(rc.path(key))
+		return "", "", false
+	}
+	return e.Text, e.Model, true
+}
+
+// Put stores a response, then evicts the oldest entries if the cache exceeds its size limit
+func (rc *ResponseCache) Put(key, model, text string) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	data, err := json.Marshal(cacheEntry{Model: model, Text: text, Created: time.Now()})
+	if err != nil {
+		return err
+	}
+	p := rc.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		return err
+	}
+	return rc.evict()
+}
+
+// evict removes expired entries, then the oldest ones until the cache fits in maxBytes
+func (rc *ResponseCache) evict() error {
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+	err := filepath.WalkDir(rc.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if rc.ttl > 0 && time.Since(info.ModTime()) > rc.ttl {
+			os.Remove(path)
+			return nil
+		}
+		files = append(files, file{path, info.Size(), info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil || rc.maxBytes <= 0 || total <= rc.maxBytes {
+		return err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= rc.maxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+	return nil
+}
+
+// Clear deletes every cached response
+func (rc *ResponseCache) Clear() error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return os.RemoveAll(rc.dir)
+}
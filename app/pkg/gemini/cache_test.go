@@ -0,0 +1,89 @@
+package gemini
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestResponseCache(t *testing.T) {
+	dir := t.TempDir()
+	contents := []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "A Plexi crunch"}}}}
+	config := &genai.GenerateContentConfig{ResponseMIMEType: "application/json"}
+
+	key := CacheKey("gemini-2.5-flash", contents, config)
+	if key != CacheKey("models/gemini-2.5-flash", contents, config) {
+		t.Errorf("CacheKey should ignore the models/ prefix")
+	}
+	if key == CacheKey("gemini-2.5-pro", contents, config) {
+		t.Errorf("CacheKey should depend on the model")
+	}
+	other := []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "A Vox chime"}}}}
+	if key == CacheKey("gemini-2.5-flash", other, config) {
+		t.Errorf("CacheKey should depend on the history")
+	}
+
+	t.Run("Round Trip", func(t *testing.T) {
+		rc := NewResponseCache(dir, time.Hour, 0)
+		if _, _, ok := rc.Get(key); ok {
+			t.Fatalf("Get() hit on an empty cache")
+		}
+		if err := rc.Put(key, "gemini-2.5-flash-lite", `{"explanation":"ok"}`); err != nil {
+			t.Fatalf("Put() error: %v", err)
+		}
+		text, model, ok := rc.Get(key)
+		if !ok || text != `{"explanation":"ok"}` || model != "gemini-2.5-flash-lite" {
+			t.Errorf("Get() = %q, %q, %v", text, model, ok)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		rc := NewResponseCache(dir, time.Nanosecond, 0)
+		rc.Put(key, "m", "text")
+		time.Sleep(time.Millisecond)
+		if _, _, ok := rc.Get(key); ok {
+			t.Errorf("Get() returned an expired entry")
+		}
+		if _, err := os.Stat(rc.path(key)); !os.IsNotExist(err) {
+			t.Errorf("expired entry should be deleted")
+		}
+	})
+
+	t.Run("Size Limit Evicts Oldest", func(t *testing.T) {
+		dir := t.TempDir()
+		rc := NewResponseCache(dir, 0, 0)
+		big := strings.Repeat("x", 100)
+		keys := []string{
+			CacheKey("a", contents, config),
+			CacheKey("b", contents, config),
+			CacheKey("c", contents, config),
+		}
+		for i, k := range keys {
+			if err := rc.Put(k, "m", big); err != nil {
+				t.Fatal(err)
+			}
+			// Distinct modification times
+			old := time.Now().Add(time.Duration(i-10) * time.Minute)
+			os.Chtimes(rc.path(k), old, old)
+		}
+		info, _ := os.Stat(rc.path(keys[2]))
+		rc.Configure(0, 2*info.Size()+info.Size()/2) // Room for two entries
+		rc.Put(keys[2], "m", big)                    // Triggers eviction with ordered mtimes
+		if _, _, ok := rc.Get(keys[0]); ok {
+			t.Errorf("oldest entry should have been evicted")
+		}
+		if _, _, ok := rc.Get(keys[2]); !ok {
+			t.Errorf("newest entry should be kept")
+		}
+		if err := rc.Clear(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, keys[2][:2])); !os.IsNotExist(err) {
+			t.Errorf("Clear() should remove the cache")
+		}
+	})
+}
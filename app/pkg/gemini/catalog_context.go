@@ -0,0 +1,142 @@
+package gemini
+
+import (
+	"HelAIx/pkg/helix"
+	"fmt"
+	"strings"
+)
+
+// candidatesPerComponent is the number of catalog models proposed for each rig component
+const candidatesPerComponent = 8
+
+// crossCategoryCandidates catches components whose "type" doesn't match the catalog category (e.g. a delay pedal typed "pedal")
+const crossCategoryCandidates = 3
+
+// componentCategories maps the Sound Engineer component types to catalog categories
+var componentCategories = map[string][]string{
+	"amp":        {helix.CategoryAmp},
+	"cab":        {helix.CategoryCab},
+	"delay":      {helix.CategoryDelay},
+	"reverb":     {helix.CategoryReverb},
+	"modulation": {helix.CategoryModulation, helix.CategoryFilter, helix.CategoryPitchSynth},
+	"pedal": {helix.CategoryDistortion, helix.CategoryDynamics, helix.CategoryEQ, helix.CategoryWah,
+		helix.CategoryVolume, helix.CategoryPitchSynth, helix.CategoryFilter, helix.CategoryModulation},
+}
+
+// buildModelContext returns the "AVAILABLE MODELS" section of the Preset Engineer prompt.
+// Instead of the whole catalog, it lists the best candidates for each component of the rig
+// (by category and Name/BasedOn similarity) plus the models already used in the conversation.
+// Components without any candidate get the full list of their categories.
+func buildModelContext(db *helix.CatalogDB, rig *RigDescription, history []ChatMessage) string {
+	db.EnsureLoaded()
+
+	var sb strings.Builder
+	listed := 0
+	for _, comp := range rig.Chain {
+		compType := strings.ToLower(strings.TrimSpace(comp.Type))
+		if strings.Contains(compType, "variax") || strings.Contains(strings.ToLower(comp.Name), "variax") {
+			continue
+		}
+		categories, ok := componentCategories[compType]
+		if !ok {
+			categories = componentCategories["pedal"]
+		}
+
+		candidates := db.Candidates(comp.Name, categories, candidatesPerComponent)
+		switch {
+		case len(candidates) == 0:
+			// FALLBACK: nothing looks similar, let the AI pick from the whole category
+			candidates = entriesOfCategories(db, categories)
+		case len(candidates) < candidatesPerComponent/2:
+			// Few close matches: top up with other models of the category to leave some choice
+			for _, e := range entriesOfCategories(db, categories) {
+				if len(candidates) >= candidatesPerComponent {
+					break
+				}
+				candidates = appendUnique(candidates, e)
+			}
+		}
+		candidates = appendUnique(candidates, db.Candidates(comp.Name, nil, crossCategoryCandidates)...)
+
+		sb.WriteString(fmt.Sprintf("\tFor %q (%s):\n", comp.Name, comp.Type))
+		for _, e := range candidates {
+			sb.WriteString("\t" + formatModelLine(e))
+		}
+		listed += len(candidates)
+	}
+
+	// STABILITY: Always offer the models chosen in previous turns
+	var previous []helix.CatalogEntry
+	for _, msg := range history {
+		if msg.Role != "assistant" {
+			continue
+		}
+		content := strings.ToLower(msg.Content)
+		for _, e := range db.All() {
+			if len(e.Name) >= 4 && strings.Contains(content, strings.ToLower(e.Name)) {
+				previous = appendUnique(previous, e)
+			}
+		}
+	}
+	if len(previous) > 0 {
+		sb.WriteString("\tPreviously used:\n")
+		for _, e := range previous {
+			sb.WriteString("\t" + formatModelLine(e))
+		}
+	}
+
+	// FALLBACK: Empty rig or no candidate at all, send the whole catalog
+	if listed == 0 {
+		return fullModelContext(db)
+	}
+	return sb.String()
+}
+
+// fullModelContext lists every model of the catalog
+func fullModelContext(db *helix.CatalogDB) string {
+	var sb strings.Builder
+	for _, e := range db.All() {
+		sb.WriteString(formatModelLine(e))
+	}
+	return sb.String()
+}
+
+// formatModelLine renders a catalog entry with its mono DSP cost for the prompt
+func formatModelLine(e helix.CatalogEntry) string {
+	cost := e.DSPMono
+	if cost == 0 {
+		cost = 3.0 // Reasonable default
+	}
+	return fmt.Sprintf("- %s (Based on: %s) [DSP: %.1f%%]\n", e.Name, e.BasedOn, cost)
+}
+
+// entriesOfCategories returns every catalog entry belonging to one of the categories
+func entriesOfCategories(db *helix.CatalogDB, categories []string) []helix.CatalogEntry {
+	var out []helix.CatalogEntry
+	for _, e := range db.All() {
+		for _, c := range categories {
+			if e.Category == c {
+				out = append(out, e)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// appendUnique appends entries that are not already in the list (by InternalName)
+func appendUnique(list []helix.CatalogEntry, entries ...helix.CatalogEntry) []helix.CatalogEntry {
+	for _, e := range entries {
+		found := false
+		for _, existing := range list {
+			if existing.InternalName == e.InternalName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, e)
+		}
+	}
+	return list
+}
@@ -0,0 +1,44 @@
+package gemini
+
+import (
+	"HelAIx/pkg/helix"
+	"strings"
+	"testing"
+)
+
+func TestBuildModelContext(t *testing.T) {
+	helix.DB.EnsureLoaded()
+	full := fullModelContext(&helix.DB)
+
+	rig := &RigDescription{
+		Chain: []RigComponent{
+			{Type: "variax", Name: "Line6 Variax"},
+			{Type: "pedal", Name: "Ibanez Tube Screamer"},
+			{Type: "amp", Name: "Marshall Plexi"},
+			{Type: "cab", Name: "Marshall 4x12 Greenback"},
+			{Type: "reverb", Name: "Spring Reverb"},
+		},
+	}
+	history := []ChatMessage{
+		{Role: "assistant", Content: `{"blocks":[{"name":"Delay","model_name":"Elephant Man"}]}`},
+	}
+
+	ctx := buildModelContext(&helix.DB, rig, history)
+	for _, want := range []string{"Scream 808", "Brit Plexi", "Greenback", "Elephant Man", "Spring"} {
+		if !strings.Contains(ctx, want) {
+			t.Errorf("context is missing %q", want)
+		}
+	}
+	if strings.Contains(ctx, "Variax") {
+		t.Errorf("context should not list candidates for the Variax")
+	}
+	if len(ctx)*3 > len(full) {
+		t.Errorf("context is %d bytes, expected well under a third of the full catalog (%d bytes)", len(ctx), len(full))
+	}
+
+	t.Run("Fallback To Full Catalog", func(t *testing.T) {
+		if got := buildModelContext(&helix.DB, &RigDescription{}, nil); got != full {
+			t.Errorf("empty rig should fall back to the full catalog")
+		}
+	})
+}
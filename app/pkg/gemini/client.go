@@ -2,13 +2,25 @@ package gemini
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 
 	"google.golang.org/genai"
 )
 
 type Client struct {
-	client    *genai.Client
-	ModelName string
+	client       *genai.Client
+	provider     Provider // Streams generations (the SDK models service, or a replay in tests)
+	ModelName    string
+	Fallbacks    []string     // Models tried in order when ModelName fails with a fallback-eligible error
+	OnProgress   ProgressFunc // Optional listener for streaming progress events
+	OnUsage      UsageFunc    // Optional listener for token usage
+	OnCacheError func(error)  // Optional listener for response cache write failures (the response is still returned)
+	Retry        RetryPolicy
+	Cache        *ResponseCache // Optional on-disk response cache
+	BypassCache  bool           // Ignore cached responses for this client (fresh responses are still stored)
+
+	transport *retryAfterTransport
 }
 
 type ChatMessage struct {
@@ -17,9 +29,11 @@ type ChatMessage struct {
 }
 
 func NewClient(ctx context.Context, apiKey string, modelName string) (*Client, error) {
+	transport := &retryAfterTransport{base: http.DefaultTransport}
 	c, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     apiKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: &http.Client{Transport: transport},
 	})
 	if err != nil {
 		return nil, err
@@ -27,22 +41,38 @@ func NewClient(ctx context.Context, apiKey string, modelName string) (*Client, e
 
 	return &Client{
 		client:    c,
+		provider:  c.Models,
 		ModelName: modelName,
+		Retry:     DefaultRetryPolicy,
+		transport: transport,
 	}, nil
 }
 
+// NewClientWithProvider returns a client generating through the given provider (e.g. a ReplayProvider).
+// ListModels is not available on such a client.
+func NewClientWithProvider(p Provider, modelName string) *Client {
+	return &Client{
+		provider:  p,
+		ModelName: modelName,
+		Retry:     DefaultRetryPolicy,
+	}
+}
+
 func (c *Client) Close() {
 	// The new SDK doesn't require explicit closing
 }
 
 // ListModels returns a list of available text and multimodal generation models
 func (c *Client) ListModels(ctx context.Context) ([]string, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("listing models requires a Gemini API client")
+	}
 	var models []string
 
 	// Use All() iterator for automatic pagination
 	for m, err := range c.client.Models.All(ctx) {
 		if err != nil {
-			return nil, err
+			return nil, classifyError(err, "")
 		}
 
 		// Filter for text/multimodal generation models
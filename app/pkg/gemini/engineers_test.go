@@ -0,0 +1,217 @@
+package gemini
+
+import (
+	"HelAIx/pkg/helix"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// go test ./pkg/gemini -run TestEngineersReplay -update rewrites the golden .hlx files.
+// With -record and GEMINI_API_KEY set, the fixtures are re-recorded against the real API first.
+var (
+	updateGolden = flag.Bool("update", false, "rewrite golden .hlx files")
+	recordReplay = flag.Bool("record", false, "re-record replay fixtures with the real Gemini API (needs GEMINI_API_KEY)")
+)
+
+func TestEngineersReplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  []ChatMessage
+		hardware string
+	}{
+		{
+			name:     "plexi_crunch",
+			history:  []ChatMessage{{Role: "user", Content: "A late 70s Marshall crunch with a Tube Screamer, a bit of tape echo for leads"}},
+			hardware: "Helix Floor",
+		},
+		{
+			name:     "tempo_delay",
+			history:  []ChatMessage{{Role: "user", Content: "Where the Streets Have No Name, with the dotted eighth delay in time"}},
+			hardware: "Helix Floor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixturePath := filepath.Join("testdata", "replay", tt.name+".json")
+			goldenPath := filepath.Join("testdata", "golden", tt.name+".hlx")
+			ctx := context.Background()
+
+			var client *Client
+			var recorder *RecordingProvider
+			if *recordReplay {
+				key := os.Getenv("GEMINI_API_KEY")
+				if key == "" {
+					t.Skip("-record needs GEMINI_API_KEY")
+				}
+				real, err := NewClient(ctx, key, "gemini-2.5-flash")
+				if err != nil {
+					t.Fatal(err)
+				}
+				recorder = &RecordingProvider{Provider: real.provider}
+				client = NewClientWithProvider(recorder, real.ModelName)
+			} else {
+				fixture, err := LoadFixture(fixturePath)
+				if err != nil {
+					t.Fatalf("LoadFixture() error: %v", err)
+				}
+				client = NewClientWithProvider(NewReplayProvider(fixture), "gemini-2.5-flash")
+			}
+
+			var events []ProgressEvent
+			client.OnProgress = func(ev ProgressEvent) { events = append(events, ev) }
+			var usage []Usage
+			client.OnUsage = func(u Usage) { usage = append(usage, u) }
+
+			rig, err := client.ChatSoundEngineer(ctx, tt.history, "Standard")
+			if err != nil {
+				t.Fatalf("ChatSoundEngineer() error: %v", err)
+			}
+			if rig.GeneratedBy != "gemini-2.5-flash" || len(rig.Chain) == 0 {
+				t.Errorf("rig = %+v", rig)
+			}
+
+			result, err := client.ChatPresetEngineer(ctx, rig, rig.SuggestedName, nil, tt.hardware, 1, false, "Standard", "3.80")
+			if err != nil {
+				t.Fatalf("ChatPresetEngineer() error: %v", err)
+			}
+			preset := result.Preset
+			if result.View.GeneratedBy != "gemini-2.5-flash" || result.View.VariaxType != "jtv" || len(result.View.DSPMap) == 0 {
+				t.Errorf("view = {%q %q %d DSP costs}", result.View.GeneratedBy, result.View.VariaxType, len(result.View.DSPMap))
+			}
+
+			if recorder != nil {
+				if err := recorder.Fixture().Save(fixturePath); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Streaming: the explanation arrives in several partial events before the blocks are mapped
+			partials, blocks := 0, 0
+			for _, ev := range events {
+				switch ev.Kind {
+				case ProgressPartial:
+					partials++
+				case ProgressBlock:
+					blocks++
+				}
+			}
+			if !*recordReplay && (partials < 2 || blocks == 0) {
+				t.Errorf("got %d partial and %d block events, want streamed progress", partials, blocks)
+			}
+			if len(usage) != 2 {
+				t.Errorf("got %d usage reports, want 2", len(usage))
+			}
+
+			if diags := helix.Validate(preset, tt.hardware); helix.HasErrors(diags) {
+				t.Errorf("generated preset is invalid: %+v", diags)
+			}
+
+			// The .hlx only carries HX Edit meta fields, plus the HelAIx metadata section
+			template, _ := helix.NewTemplatePreset(rig.SuggestedName)
+			templateMeta := (*template)["data"].(map[string]interface{})["meta"].(map[string]interface{})
+			for key := range (*preset)["data"].(map[string]interface{})["meta"].(map[string]interface{}) {
+				if _, ok := templateMeta[key]; !ok && key != helix.MetadataKey {
+					t.Errorf("meta has UI key %q", key)
+				}
+			}
+
+			got, err := json.MarshalIndent(preset, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *updateGolden || *recordReplay {
+				if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("missing golden file (run with -update): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("preset differs from %s (run with -update if the change is intended)", goldenPath)
+			}
+		})
+	}
+}
+
+func TestReplayFallback(t *testing.T) {
+	fixture := &Fixture{Calls: []FixtureCall{
+		{Model: "gemini-2.5-pro", Error: &genai.APIError{Code: 503, Message: "The model is overloaded"}},
+		{Model: "gemini-2.5-pro", Error: &genai.APIError{Code: 429, Details: []map[string]any{
+			{"@type": "type.googleapis.com/google.rpc.QuotaFailure", "violations": []any{map[string]any{"quotaId": "GenerateRequestsPerDayPerProjectPerModel"}}},
+		}}},
+		{Model: "gemini-2.5-flash", Response: `{"suggested_name": "Fallback", "explanation": "ok", "chain": []}`},
+	}}
+	provider := NewReplayProvider(fixture)
+	provider.Strict = true
+	client := NewClientWithProvider(provider, "gemini-2.5-pro")
+	client.Fallbacks = []string{"gemini-2.5-flash"}
+	client.Retry.BaseDelay = time.Millisecond
+
+	var kinds []string
+	client.OnProgress = func(ev ProgressEvent) { kinds = append(kinds, ev.Kind) }
+
+	rig, err := client.ChatSoundEngineer(context.Background(), nil, "Standard")
+	if err != nil {
+		t.Fatalf("ChatSoundEngineer() error: %v", err)
+	}
+	if rig.GeneratedBy != "gemini-2.5-flash" {
+		t.Errorf("GeneratedBy = %s, want the fallback model", rig.GeneratedBy)
+	}
+	if provider.Remaining() != 0 {
+		t.Errorf("%d recorded calls not replayed", provider.Remaining())
+	}
+	if got := strings.Join(kinds, ","); got != "started,retry,fallback,partial,done" {
+		t.Errorf("progress = %s", got)
+	}
+
+	t.Run("Safety Block Is Not Retried", func(t *testing.T) {
+		client := NewClientWithProvider(NewReplayProvider(&Fixture{Calls: []FixtureCall{
+			{Response: "{", FinishReason: genai.FinishReasonSafety},
+		}}), "gemini-2.5-flash")
+		_, err := client.ChatSoundEngineer(context.Background(), nil, "Standard")
+		if err == nil || !strings.Contains(err.Error(), string(ErrSafetyBlock)) {
+			t.Errorf("error = %v, want a safety block", err)
+		}
+	})
+}
+
+func TestUnparsableResponseIsNotCached(t *testing.T) {
+	good := `{"suggested_name": "Good", "explanation": "ok", "chain": []}`
+	provider := NewReplayProvider(&Fixture{Calls: []FixtureCall{
+		{Model: "gemini-2.5-flash", Response: `{"suggested_name": "Trunc`},
+		{Model: "gemini-2.5-flash", Response: good},
+	}})
+	client := NewClientWithProvider(provider, "gemini-2.5-flash")
+	client.Cache = NewResponseCache(t.TempDir(), time.Hour, 0)
+
+	if _, err := client.ChatSoundEngineer(context.Background(), nil, "Standard"); err == nil {
+		t.Fatalf("ChatSoundEngineer() should fail on a truncated response")
+	}
+	rig, err := client.ChatSoundEngineer(context.Background(), nil, "Standard")
+	if err != nil {
+		t.Fatalf("the truncated response was served again: %v", err)
+	}
+	if rig.SuggestedName != "Good" || provider.Remaining() != 0 {
+		t.Errorf("second call should reach the provider, got %q with %d calls left", rig.SuggestedName, provider.Remaining())
+	}
+
+	// The parsed response is cached: the provider has no call left to replay
+	if rig, err := client.ChatSoundEngineer(context.Background(), nil, "Standard"); err != nil || rig.SuggestedName != "Good" {
+		t.Errorf("parsed response should be served from the cache, got %v", err)
+	}
+}
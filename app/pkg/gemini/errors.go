@@ -0,0 +1,188 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// ErrorKind classifies provider errors so the UI can show a localized message
+type ErrorKind string
+
+const (
+	ErrQuotaExhausted ErrorKind = "quota_exhausted" // Daily/monthly quota used up, retrying won't help
+	ErrRateLimited    ErrorKind = "rate_limited"    // Too many requests per minute, retry later
+	ErrInvalidKey     ErrorKind = "invalid_key"
+	ErrModelNotFound  ErrorKind = "model_not_found"
+	ErrSafetyBlock    ErrorKind = "safety_block"
+	ErrUnavailable    ErrorKind = "unavailable" // Transient 5xx (overloaded, timeout)
+	ErrProvider       ErrorKind = "provider_error"
+)
+
+// Error is a classified provider error
+type Error struct {
+	Kind       ErrorKind
+	Model      string
+	Message    string
+	RetryAfter time.Duration // Delay suggested by the provider, 0 if unknown
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the same request may succeed later
+func (e *Error) Retryable() bool {
+	return e.Kind == ErrRateLimited || e.Kind == ErrUnavailable
+}
+
+// FallbackEligible reports whether another model may succeed where this one failed
+// (quotas and availability are per model; a bad key or a blocked prompt are not)
+func (e *Error) FallbackEligible() bool {
+	switch e.Kind {
+	case ErrQuotaExhausted, ErrRateLimited, ErrModelNotFound, ErrUnavailable:
+		return true
+	}
+	return false
+}
+
+// classifyError converts an SDK error into an *Error. Context errors are returned unchanged.
+func classifyError(err error, model string) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+
+	gerr := &Error{Kind: ErrProvider, Model: model, Message: err.Error(), Err: err}
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return gerr
+	}
+	gerr.Message = apiErr.Message
+	gerr.RetryAfter = retryDelayFromDetails(apiErr.Details)
+
+	switch {
+	case apiErr.Code == http.StatusTooManyRequests:
+		gerr.Kind = ErrRateLimited
+		if isDailyQuota(apiErr.Details) {
+			gerr.Kind = ErrQuotaExhausted
+		}
+	case apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusForbidden:
+		gerr.Kind = ErrInvalidKey
+	case apiErr.Code == http.StatusBadRequest && strings.Contains(strings.ToLower(apiErr.Message), "api key"):
+		gerr.Kind = ErrInvalidKey
+	case apiErr.Code == http.StatusNotFound:
+		gerr.Kind = ErrModelNotFound
+	case apiErr.Code >= 500:
+		gerr.Kind = ErrUnavailable
+	}
+	return gerr
+}
+
+// safetyError returns an ErrSafetyBlock error if the response was blocked, nil otherwise
+func safetyError(resp *genai.GenerateContentResponse, model string) error {
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" && resp.PromptFeedback.BlockReason != genai.BlockedReasonUnspecified {
+		return &Error{Kind: ErrSafetyBlock, Model: model, Message: fmt.Sprintf("prompt blocked (%s)", resp.PromptFeedback.BlockReason)}
+	}
+	if len(resp.Candidates) > 0 {
+		switch reason := resp.Candidates[0].FinishReason; reason {
+		case genai.FinishReasonSafety, genai.FinishReasonProhibitedContent, genai.FinishReasonBlocklist, genai.FinishReasonSPII:
+			return &Error{Kind: ErrSafetyBlock, Model: model, Message: fmt.Sprintf("response blocked (%s)", reason)}
+		}
+	}
+	return nil
+}
+
+// isDailyQuota checks the QuotaFailure details for a per-day (or longer) quota
+func isDailyQuota(details []map[string]any) bool {
+	for _, d := range details {
+		if t, _ := d["@type"].(string); !strings.HasSuffix(t, "QuotaFailure") {
+			continue
+		}
+		violations, _ := d["violations"].([]any)
+		for _, v := range violations {
+			vm, _ := v.(map[string]any)
+			id, _ := vm["quotaId"].(string)
+			if strings.Contains(id, "PerDay") || strings.Contains(id, "PerMonth") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// retryDelayFromDetails reads the google.rpc.RetryInfo delay (e.g. "37s")
+func retryDelayFromDetails(details []map[string]any) time.Duration {
+	for _, d := range details {
+		if t, _ := d["@type"].(string); !strings.HasSuffix(t, "RetryInfo") {
+			continue
+		}
+		if s, ok := d["retryDelay"].(string); ok {
+			if delay, err := time.ParseDuration(s); err == nil {
+				return delay
+			}
+		}
+	}
+	return 0
+}
+
+// retryAfterTransport records the Retry-After header of throttled responses,
+// which the SDK does not expose on its errors
+type retryAfterTransport struct {
+	base http.RoundTripper
+
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		t.mu.Lock()
+		t.delay = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		t.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// lastRetryAfter returns (and clears) the last recorded Retry-After delay
+func (t *retryAfterTransport) lastRetryAfter() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	d := t.delay
+	t.delay = 0
+	return d
+}
+
+// parseRetryAfter parses a Retry-After header (seconds or HTTP date)
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,138 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestClassifyError(t *testing.T) {
+	dailyQuota := []map[string]any{
+		{"@type": "type.googleapis.com/google.rpc.QuotaFailure", "violations": []any{
+			map[string]any{"quotaId": "GenerateRequestsPerDayPerProjectPerModel-FreeTier"},
+		}},
+	}
+	minuteQuota := []map[string]any{
+		{"@type": "type.googleapis.com/google.rpc.QuotaFailure", "violations": []any{
+			map[string]any{"quotaId": "GenerateRequestsPerMinutePerProjectPerModel-FreeTier"},
+		}},
+		{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "37s"},
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantKind   ErrorKind
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		{"Daily Quota", genai.APIError{Code: 429, Details: dailyQuota}, ErrQuotaExhausted, false, 0},
+		{"Rate Limit With Retry Info", genai.APIError{Code: 429, Details: minuteQuota}, ErrRateLimited, true, 37 * time.Second},
+		{"Invalid Key", genai.APIError{Code: 400, Message: "API key not valid. Please pass a valid API key."}, ErrInvalidKey, false, 0},
+		{"Permission Denied", genai.APIError{Code: 403}, ErrInvalidKey, false, 0},
+		{"Model Not Found", genai.APIError{Code: 404, Message: "models/gemini-0.1 is not found"}, ErrModelNotFound, false, 0},
+		{"Overloaded", genai.APIError{Code: 503, Message: "The model is overloaded"}, ErrUnavailable, true, 0},
+		{"Wrapped", fmt.Errorf("stream: %w", genai.APIError{Code: 500}), ErrUnavailable, true, 0},
+		{"Other Bad Request", genai.APIError{Code: 400, Message: "Invalid JSON payload"}, ErrProvider, false, 0},
+		{"Network Error", errors.New("connection reset"), ErrProvider, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gerr *Error
+			if !errors.As(classifyError(tt.err, "gemini-2.5-flash"), &gerr) {
+				t.Fatalf("classifyError() did not return an *Error")
+			}
+			if gerr.Kind != tt.wantKind || gerr.Retryable() != tt.retryable || gerr.RetryAfter != tt.retryAfter {
+				t.Errorf("got kind=%s retryable=%v retryAfter=%v, want %s/%v/%v",
+					gerr.Kind, gerr.Retryable(), gerr.RetryAfter, tt.wantKind, tt.retryable, tt.retryAfter)
+			}
+		})
+	}
+
+	t.Run("Context Errors Unchanged", func(t *testing.T) {
+		if err := classifyError(context.Canceled, ""); err != context.Canceled {
+			t.Errorf("classifyError(context.Canceled) = %v", err)
+		}
+	})
+}
+
+func TestSafetyError(t *testing.T) {
+	blocked := &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonSafety}},
+	}
+	var gerr *Error
+	if !errors.As(safetyError(blocked, ""), &gerr) || gerr.Kind != ErrSafetyBlock {
+		t.Errorf("safetyError() should report a safety block")
+	}
+	ok := &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonStop}},
+	}
+	if err := safetyError(ok, ""); err != nil {
+		t.Errorf("safetyError() = %v, want nil", err)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 4, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+	for attempt := 1; attempt <= 6; attempt++ {
+		delay, ok := p.backoff(attempt, 0)
+		want := time.Second << (attempt - 1)
+		if want > p.MaxDelay {
+			want = p.MaxDelay
+		}
+		if !ok || delay < want/2 || delay > want {
+			t.Errorf("backoff(%d) = %v, want within [%v, %v]", attempt, delay, want/2, want)
+		}
+	}
+
+	if delay, ok := p.backoff(1, 10*time.Second); !ok || delay != 10*time.Second {
+		t.Errorf("Retry-After should be respected, got %v", delay)
+	}
+	if _, ok := p.backoff(1, time.Minute); ok {
+		t.Errorf("Retry-After above MaxDelay should give up")
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	if d := parseRetryAfter("20", now); d != 20*time.Second {
+		t.Errorf("parseRetryAfter(20) = %v", d)
+	}
+	if d := parseRetryAfter(now.Add(5*time.Second).Format(http.TimeFormat), now); d != 5*time.Second {
+		t.Errorf("parseRetryAfter(date) = %v", d)
+	}
+}
+
+func TestModelChain(t *testing.T) {
+	c := &Client{
+		ModelName: "gemini-2.5-flash",
+		Fallbacks: []string{"models/gemini-2.5-flash-lite", " ", "gemini-2.5-flash", "gemini-2.0-flash"},
+	}
+	got := c.modelChain()
+	want := []string{"gemini-2.5-flash", "gemini-2.5-flash-lite", "gemini-2.0-flash"}
+	if len(got) != len(want) {
+		t.Fatalf("modelChain() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("modelChain()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	for kind, eligible := range map[ErrorKind]bool{
+		ErrQuotaExhausted: true,
+		ErrModelNotFound:  true,
+		ErrUnavailable:    true,
+		ErrInvalidKey:     false,
+		ErrSafetyBlock:    false,
+	} {
+		if (&Error{Kind: kind}).FallbackEligible() != eligible {
+			t.Errorf("%s.FallbackEligible() != %v", kind, eligible)
+		}
+	}
+}
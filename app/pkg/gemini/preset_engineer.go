@@ -4,6 +4,7 @@ import (
 	"HelAIx/pkg/helix"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -11,23 +12,32 @@ import (
 	"google.golang.org/genai"
 )
 
-// ChatPresetEngineer takes the abstract rig and maps it to specific Helix Blocks, or refines an existing implementation
-func (c *Client) ChatPresetEngineer(ctx context.Context, rig *RigDescription, presetName string, history []ChatMessage, hardware string, defaultExp int, variaxEnabled bool, hardwareModel string) (*helix.Preset, error) {
-	// 1. Prepare Catalog Context
-	helix.DB.EnsureLoaded()
+// PresetView is the data the UI needs to display a preset. It is kept out of the .hlx file.
+type PresetView struct {
+	DSPMap      map[string]float64 `json:"dsp_map"`      // Model ID -> DSP cost, for the DSP meters
+	VariaxType  string             `json:"variax_type"`  // "jtv" or "shuriken", selects the Variax bank labels
+	GeneratedBy string             `json:"generated_by"` // Model that actually answered (may be a fallback)
+}
 
-	// Create a list of available models with their mono DSP costs
-	var availableModels strings.Builder
-	for _, e := range helix.DB.Entries {
-		cost := e.DSPMono
-		if cost == 0 {
-			cost = 3.0 // Reasonable default
-		}
-		availableModels.WriteString(fmt.Sprintf("- %s (Based on: %s) [DSP: %.1f%%]\n", e.Name, e.BasedOn, cost))
+// PresetResult is the answer of the Preset Engineer: the preset to export and its UI data
+type PresetResult struct {
+	Preset *helix.Preset `json:"preset"`
+	View   PresetView    `json:"view"`
+}
+
+// ChatPresetEngineer takes the abstract rig and maps it to specific Helix Blocks, or refines an existing implementation
+func (c *Client) ChatPresetEngineer(ctx context.Context, rig *RigDescription, presetName string, history []ChatMessage, hardware string, defaultExp int, variaxEnabled bool, hardwareModel string, firmware string) (*PresetResult, error) {
+	// 1. Prepare Catalog Context (only models available on the target firmware)
+	db, err := helix.CatalogFor(firmware)
+	if err != nil {
+		return nil, err
 	}
 
+	// Shortlist of candidate models (with their mono DSP costs) for each component of the rig
+	availableModels := buildModelContext(db, rig, history)
+
 	// 2. Hardware Capabilities
-	isDualDSP := strings.Contains(hardware, "Floor") || strings.Contains(hardware, "LT") || strings.Contains(hardware, "Rack")
+	isDualDSP := helix.IsDualDSP(hardware)
 	dspCapacity := "1 path of 100%"
 	if isDualDSP {
 		dspCapacity = "2 paths (Path 1 and Path 2), each with its own 100% DSP chip. Total 200%."
@@ -38,10 +48,11 @@ func (c *Client) ChatPresetEngineer(ctx context.Context, rig *RigDescription, pr
 	You will receive a "Rig Description" (abstract design) and the conversation history.
 	Your job is to MAP each item to the BEST MATCHING Available Helix Model from the provided list.
 	
-	TARGET HARDWARE: %s
+	TARGET HARDWARE: %s (%d expression pedals)
+	TARGET FIRMWARE: %s (ONLY use models from the list below, newer models do not exist on this firmware)
 	DSP CAPACITY: %s
 	
-	AVAILABLE MODELS:
+	AVAILABLE MODELS (best candidates for each component of the rig):
 	%s
 	
 	CONVERSATION LOGIC:
@@ -60,6 +71,17 @@ func (c *Client) ChatPresetEngineer(ctx context.Context, rig *RigDescription, pr
 
 	PARAMETER CONSTRAINTS:
 	- For Reverb blocks, NEVER set "Decay" or "VerbDecay" to its maximum value (1.0). Keep it at 0.7 or lower to avoid excessive noise/feedback loops.
+	- Components with a "note_division" are synced to the song tempo automatically: do not set "Time", "TempoSync" or "SyncSelect" on them.
+
+	EXPRESSION PEDALS:
+	- Wahs, volume pedals and whammies are assigned to the default pedal automatically.
+	- When the user asks for a pedal-controlled parameter (e.g. delay mix, reverb mix, drive, filter frequency), add an "expression" array to the block: { "param": "Mix", "pedal": 2, "heel": 0.1, "toe": 0.5 }.
+	- "pedal" is 1, 2 or 3 (EXP 1, EXP 2, EXP 3) and MUST NOT exceed the number of pedals of the hardware. "heel" and "toe" are the values at each end of the pedal travel.
+	- Set "auto_engage": true on a wah to turn it on with the pedal toe switch.
+
+	MIDI:
+	- When the user wants a parameter driven by incoming MIDI CC, add a "midi_cc" array to the block: { "param": "Mix", "cc": 20, "min": 0, "max": 0.6 }. CCs 0-3, 32 and 49-71 are reserved by Helix.
+	- Leave it out when the user does not mention MIDI.
 
 	VARIAX AND INPUTS:
 	- CRITICAL: Variax is NOT an effect block. It is a GLOBAL INPUT SETTING.
@@ -76,10 +98,11 @@ func (c *Client) ChatPresetEngineer(ctx context.Context, rig *RigDescription, pr
 	OUTPUT FORMAT:
 	{
 		"blocks": [
-			{ "name": "Tube Screamer", "model_name": "Scream 808", "path": 0, "params": { "Gain": 0.5 } }
+			{ "name": "Tube Screamer", "model_name": "Scream 808", "path": 0, "params": { "Gain": 0.5 } },
+			{ "name": "Tape Echo", "model_name": "Transistor Tape", "path": 0, "params": { "Mix": 0.2 }, "expression": [{ "param": "Mix", "pedal": 2, "heel": 0.1, "toe": 0.5 }] }
 		]
 	}
-	`, hardware, dspCapacity, availableModels.String())
+	`, hardware, helix.ExpressionPedals(hardware), db.Firmware, dspCapacity, availableModels)
 
 	// Truncate prompt if needed (though Gemini 1.5 Handle this well)
 	if len(sysPrompt) > 100000 {
@@ -119,17 +142,15 @@ func (c *Client) ChatPresetEngineer(ctx context.Context, rig *RigDescription, pr
 		ResponseMIMEType: "application/json",
 	}
 
-	resp, err := c.client.Models.GenerateContent(ctx, c.ModelName, contents, config)
+	jsonText, model, cacheKey, err := c.generateStream(ctx, StagePresetEngineer, contents, config)
 	if err != nil {
-		return nil, fmt.Errorf("preset engineer agent failed: %v", err)
+		return nil, fmt.Errorf("preset engineer agent failed: %w", err)
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
+	if jsonText == "" {
 		return nil, fmt.Errorf("empty response from Preset Engineer Agent")
 	}
 
-	jsonText := resp.Candidates[0].Content.Parts[0].Text
-
 	// 4. PRE-FLIGHT VARIAX SYNC: Ensure top-level fields are sync'd with Chain components
 	// (Agents are more reliable at updating the Chain/Params than top-level technical fields)
 	variaxCompName := ""
@@ -158,388 +179,35 @@ func (c *Client) ChatPresetEngineer(ctx context.Context, rig *RigDescription, pr
 	}
 
 	// 5. Parse Response
-	type BuilderBlock struct {
-		Name      string                 `json:"name"`
-		ModelName string                 `json:"model_name"`
-		Path      int                    `json:"path"`
-		Params    map[string]interface{} `json:"params"`
+	var builderResp struct {
+		Blocks []helix.BlockPlan `json:"blocks"`
 	}
-	type BuilderResponse struct {
-		Blocks []BuilderBlock `json:"blocks"`
-	}
-
-	var builderResp BuilderResponse
 	if err := json.Unmarshal([]byte(jsonText), &builderResp); err != nil {
 		return nil, fmt.Errorf("failed to parse Preset Engineer JSON: %v. Raw: %s", err, jsonText)
 	}
+	c.storeResponse(cacheKey, model, jsonText)
 
 	// 6. Construct The Real Preset via Template
 	preset, err := helix.NewTemplatePreset(presetName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create preset from template: %v", err)
 	}
-
-	// Helper to get dsp map
-	getDSP := func(path int) map[string]interface{} {
-		key := fmt.Sprintf("dsp%d", path)
-		if data, ok := (*preset)["data"].(map[string]interface{}); ok {
-			if tone, ok := data["tone"].(map[string]interface{}); ok {
-				if d, ok := tone[key].(map[string]interface{}); ok {
-					return d
-				}
-			}
-		}
-		return nil
+	builder := helix.NewBuilder(preset, db, isDualDSP)
+	if err := c.placeBlocks(builder, rig, builderResp.Blocks, defaultExp, hardware); err != nil {
+		return nil, err
 	}
 
-	// Helper to get controller map for a specific path
-	getController := func(path int) map[string]interface{} {
-		key := fmt.Sprintf("dsp%d", path)
-		if data, ok := (*preset)["data"].(map[string]interface{}); ok {
-			if tone, ok := data["tone"].(map[string]interface{}); ok {
-				if ctrl, ok := tone["controller"].(map[string]interface{}); ok {
-					if d, ok := ctrl[key].(map[string]interface{}); ok {
-						return d
-					}
-				}
-			}
+	// INPUT: gate and impedance asked by the Sound Engineer, the gate settings it leaves out following the gain
+	if highGain := builder.HighGain(); rig.Input != nil || highGain {
+		base := helix.DefaultInput
+		if highGain {
+			base = helix.HighGainInput
 		}
-		return nil
-	}
-
-	// Loop and place blocks
-	path0Count := 0
-	path1Count := 0
-
-	for _, b := range builderResp.Blocks {
-		// VIRTUAL BLOCK SKIP: Variax is handled globally via global/snapshot logic
-		if strings.Contains(strings.ToLower(b.Name), "variax") || strings.Contains(strings.ToLower(b.ModelName), "variax") {
-			continue
-		}
-
-		entry, found := helix.DB.FindByRealName(b.ModelName)
-		var internalID string
-		var defaultData map[string]interface{}
-
-		if found {
-			internalID = entry.InternalName
-			defaultData = entry.Data["Defaults"].(map[string]interface{})
-		} else {
-			entryID, foundID := helix.DB.FindByID(b.ModelName)
-			if foundID {
-				internalID = entryID.InternalName
-				defaultData = entryID.Data["Defaults"].(map[string]interface{})
-			} else {
-				continue
-			}
-		}
-
-		finalParams := make(map[string]interface{})
-		for k, v := range defaultData {
-			finalParams[k] = v
-		}
-		finalParams["@name"] = b.Name
-		finalParams["@model"] = internalID
-		finalParams["@enabled"] = true
-
-		// GET REFERENCE to clean name based on model
-		technicalName := strings.ReplaceAll(strings.ReplaceAll(internalID, "HD2_", ""), "VIC_", "")
-		technicalName = strings.ReplaceAll(technicalName, "_", " ")
-
-		// Target Path (DSP Instance)
-		targetPath := b.Path
-		if !isDualDSP {
-			targetPath = 0
-		}
-		// Sub-path (A/B) is locked to A (0) to avoid unnecessary split blocks
-		subPath := 0
-
-		// Position
-		pos := 0
-		if targetPath == 1 {
-			pos = path1Count
-			path1Count++
-		} else {
-			pos = path0Count
-			path0Count++
-		}
-		finalParams["@position"] = pos
-
-		// Type mapping
-		switch {
-		case strings.HasPrefix(internalID, "HD2_Amp"):
-			finalParams["@type"] = 1
-		case strings.HasPrefix(internalID, "HD2_Preamp"):
-			finalParams["@type"] = 2
-		case strings.HasPrefix(internalID, "HD2_Cab") || strings.HasPrefix(internalID, "VIC_Cab"):
-			finalParams["@type"] = 2
-		case strings.HasPrefix(internalID, "HD2_Delay") || strings.HasPrefix(internalID, "HD2_Reverb") || strings.HasPrefix(internalID, "VIC_Reverb"):
-			finalParams["@type"] = 7
-		default:
-			finalParams["@type"] = 0
-		}
-
-		for pName, pVal := range b.Params {
-			pVal = sanitizeParam(internalID, pName, pVal)
-			key := pName
-
-			// Heuristic for matching generic names to technical names
-			if _, exists := defaultData[key]; !exists {
-				lowName := strings.ToLower(pName)
-				found := false
-				alternatives := []string{}
-				if lowName == "gain" {
-					alternatives = []string{"Drive", "LeadGain", "Lead Drive", "ChVol", "Master"}
-				} else if lowName == "drive" {
-					alternatives = []string{"Gain", "LeadDrive", "Lead Gain", "Overdrive"}
-				} else if lowName == "volume" || lowName == "vol" {
-					alternatives = []string{"ChVol", "Master", "Level"}
-				} else if lowName == "mids" {
-					alternatives = []string{"Middle", "Mid"}
-				}
-
-				for _, alt := range alternatives {
-					if _, exists := defaultData[alt]; exists {
-						key = alt
-						found = true
-						break
-					}
-				}
-
-				if !found {
-					for actualKey := range defaultData {
-						if strings.EqualFold(actualKey, pName) {
-							key = actualKey
-							break
-						}
-					}
-				}
-			}
-			finalParams[key] = pVal
-		}
-
-		// ENSURE ROUTING: Force sub-path 0 after AI params loop to prevent AI overwrites
-		finalParams["@path"] = subPath
-
-		// Place in correct DSP
-		dsp := getDSP(targetPath)
-		if dsp != nil {
-			// Placing block in the correct DSP map
-			blockKey := fmt.Sprintf("block%d", pos)
-			dsp[blockKey] = finalParams
-
-			// SYNC FIX: If we are processing Snapshot 0, update the main block's enabled state
-			isUsedInAnySnapshot := false
-			if len(rig.Snapshots) > 0 {
-				for sIdx := range rig.Snapshots {
-					snapshot := &rig.Snapshots[sIdx]
-					for _, activeName := range snapshot.ActiveBlocks {
-						an := strings.ToLower(activeName)
-						bn := strings.ToLower(b.Name)
-						if an == bn || strings.Contains(an, bn) || strings.Contains(bn, an) {
-							isUsedInAnySnapshot = true
-							break
-						}
-					}
-					if isUsedInAnySnapshot {
-						break
-					}
-				}
-
-				// If block is totally unused, force it enabled in the first snapshot
-				if !isUsedInAnySnapshot && len(rig.Snapshots) > 0 {
-					rig.Snapshots[0].ActiveBlocks = append(rig.Snapshots[0].ActiveBlocks, b.Name)
-				}
-			}
-
-			// Default Expression Pedal Assignment
-			if defaultExp > 0 {
-				isWah := strings.HasPrefix(internalID, "HD2_Wah")
-				isVol := strings.HasPrefix(internalID, "HD2_Vol")
-				isPitch := strings.HasPrefix(internalID, "HD2_PitchPitchWham")
-				if isWah || isVol || isPitch {
-					ctrls := getController(targetPath)
-					if ctrls != nil {
-						ctrls[blockKey] = map[string]interface{}{
-							"Pedal": map[string]interface{}{
-								"@controller":       defaultExp,
-								"@max":              1.0,
-								"@min":              0.0,
-								"@snapshot_disable": false,
-							},
-						}
-					}
-				}
-			}
-
-			// Sync snapshot
-			if data, ok := (*preset)["data"].(map[string]interface{}); ok {
-				if tone, ok := data["tone"].(map[string]interface{}); ok {
-					if len(rig.Snapshots) > 0 {
-						// Logic for custom snapshots
-						for s := 0; s < 8; s++ {
-							snapKey := fmt.Sprintf("snapshot%d", s)
-							if snap, ok := tone[snapKey].(map[string]interface{}); ok {
-								// Set snapshot name from AI proposal (up to first 4 slots)
-								if s < len(rig.Snapshots) {
-									snap["@name"] = rig.Snapshots[s].Name
-									snap["@custom_name"] = true
-								}
-
-								// 1. Handle Bypass states
-								if blocks, ok := snap["blocks"].(map[string]interface{}); ok {
-									dspKey := fmt.Sprintf("dsp%d", targetPath)
-									// INITIALIZATION FIX: Ensure dsp map exists for Path 2 blocks
-									if _, exists := blocks[dspKey]; !exists {
-										blocks[dspKey] = make(map[string]interface{})
-									}
-
-									if targetPathMap, ok := blocks[dspKey].(map[string]interface{}); ok {
-										// Enable block only if it's in the snapshot's active_blocks
-										isEnabled := false
-										if s < len(rig.Snapshots) {
-											for _, activeName := range rig.Snapshots[s].ActiveBlocks {
-												a := strings.ToLower(activeName)
-												// Check against both user name and technical model name for robustness
-												bName := strings.ToLower(b.Name)
-												bModel := strings.ToLower(b.ModelName)
-												if a == bName || a == bModel || strings.Contains(bName, a) || strings.Contains(a, bName) || strings.Contains(bModel, a) || strings.Contains(a, bModel) {
-													isEnabled = true
-													break
-												}
-											}
-										}
-
-										// SYNC FIX: If we are processing Snapshot 0, update the main block's enabled state
-										if s == 0 {
-											if bMap, ok := dsp[blockKey].(map[string]interface{}); ok {
-												bMap["@enabled"] = isEnabled
-											}
-										}
-
-										targetPathMap[blockKey] = isEnabled
-									}
-								}
-
-								// 2. Handle Parameter Overrides
-								if s < len(rig.Snapshots) && rig.Snapshots[s].Params != nil {
-									snapshot := rig.Snapshots[s]
-									if overrides, ok := snapshot.Params[b.Name].(map[string]interface{}); ok {
-										for pName, pVal := range overrides {
-											// Map parameter name to technical Helix key
-											pKey := pName
-											// ... (pKey resolution logic follows)
-
-											// 1. Try exact match or common aliases
-											if _, exists := defaultData[pKey]; !exists {
-												// Heuristic for matching "Gain" or "Drive" to specific amp parameters
-												lowName := strings.ToLower(pName)
-												foundMatch := false
-
-												// List of alternatives to try
-												alternatives := []string{}
-												if lowName == "gain" {
-													alternatives = []string{"Drive", "LeadGain", "Lead Drive", "ChVol", "Master"}
-												} else if lowName == "drive" {
-													alternatives = []string{"Gain", "LeadDrive", "Lead Gain", "Overdrive"}
-												} else if lowName == "volume" || lowName == "vol" {
-													alternatives = []string{"ChVol", "Master", "Level"}
-												} else if lowName == "mids" {
-													alternatives = []string{"Middle", "Mid"}
-												}
-
-												for _, alt := range alternatives {
-													if _, exists := defaultData[alt]; exists {
-														pKey = alt
-														foundMatch = true
-														break
-													}
-												}
-
-												// 2. If no alias found, try a case-insensitive lookup
-												if !foundMatch {
-													for actualKey := range defaultData {
-														if strings.EqualFold(actualKey, pName) {
-															pKey = actualKey
-															break
-														}
-													}
-												}
-											}
-
-											// OPTIMIZATION: Check if this parameter actually varies across any snapshot or from baseline
-											// Only add controller if it actually changes something.
-											shouldControl := false
-											baselineVal := finalParams[pKey]
-											for _, otherSnap := range rig.Snapshots {
-												if otherOverrides, ok := otherSnap.Params[b.Name].(map[string]interface{}); ok {
-													if otherVal, ok := otherOverrides[pName]; ok {
-														// Sanitize otherVal to match the scale of baselineVal
-														sOtherVal := sanitizeParam(internalID, pKey, otherVal)
-														if sOtherVal != baselineVal {
-															shouldControl = true
-															break
-														}
-													}
-												}
-											}
-
-											if shouldControl {
-												// Apply snapshot controller global map
-												ctrls := getController(targetPath)
-												if ctrls != nil {
-													if _, ok := ctrls[blockKey]; !ok {
-														ctrls[blockKey] = make(map[string]interface{})
-													}
-													if bCtrls, ok := ctrls[blockKey].(map[string]interface{}); ok {
-														bCtrls[pKey] = map[string]interface{}{
-															"@controller":       9, // Snapshot Controller
-															"@max":              1.0,
-															"@min":              0.0,
-															"@snapshot_disable": false,
-														}
-													}
-												}
-												// Set snapshot-specific value
-												if snapCtrls, ok := snap["controllers"].(map[string]interface{}); ok {
-													dspKey := fmt.Sprintf("dsp%d", targetPath)
-													if _, ok := snapCtrls[dspKey]; !ok {
-														snapCtrls[dspKey] = make(map[string]interface{})
-													}
-													if sDsp, ok := snapCtrls[dspKey].(map[string]interface{}); ok {
-														if _, ok := sDsp[blockKey]; !ok {
-															sDsp[blockKey] = make(map[string]interface{})
-														}
-														if sBlock, ok := sDsp[blockKey].(map[string]interface{}); ok {
-															sBlock[pKey] = map[string]interface{}{
-																"@value": pVal,
-															}
-														}
-													}
-												}
-											}
-										}
-									}
-								}
-							}
-						}
-					} else {
-						// Default logic (Legacy/No Snapshots): Enable in all 8
-						for s := 0; s < 8; s++ {
-							snapKey := fmt.Sprintf("snapshot%d", s)
-							if snap, ok := tone[snapKey].(map[string]interface{}); ok {
-								if blocks, ok := snap["blocks"].(map[string]interface{}); ok {
-									dspKey := fmt.Sprintf("dsp%d", targetPath)
-									if d, ok := blocks[dspKey].(map[string]interface{}); ok {
-										d[blockKey] = true
-									}
-								}
-							}
-						}
-					}
-				}
-			}
+		input := base
+		if rig.Input != nil {
+			input = rig.Input.Over(base)
 		}
+		builder.SetInput(input, hardware)
 	}
 
 	// 5. Detect Variax Intent and Apply
@@ -599,49 +267,189 @@ func (c *Client) ChatPresetEngineer(ctx context.Context, rig *RigDescription, pr
 		data["@device"] = deviceID
 		data["@schema"] = 0
 
-		// EXPOSE DSP MAP: Include model->DSP costs for UI visualization
-		dspMap := make(map[string]float64)
-		for _, e := range helix.DB.Entries {
-			cost := e.DSPMono
-			if cost == 0 {
-				cost = 3.0
+		// PROVENANCE: where the preset comes from, in a section HX Edit ignores
+		preset.SetMetadata(helix.Metadata{
+			Description: rig.Explanation,
+			Genre:       rig.Genre,
+			Tags:        rig.Tags,
+			Prompt:      rig.Prompt,
+			Rig:         rig.Summary(),
+			Model:       model,
+			Firmware:    db.Firmware,
+			Hardware:    hardware,
+		})
+
+		if tone, ok := data["tone"].(map[string]interface{}); ok {
+			// HARDWARE OUTPUTS: Force Multi Output by default instead of Native-Host (15)
+			builder.SetOutput(0, helix.OutputMulti)
+			builder.SetOutput(1, helix.OutputMulti)
+			// SERIAL INTERCONNECT: Route DSP0 to Path 2 only if Path 2 is actually used
+			if isDualDSP && builder.Count(1) > 0 {
+				builder.SetOutput(0, helix.OutputPath2)
+			}
+
+			if global, ok := tone["global"].(map[string]interface{}); ok {
+				global["@cursor_dsp"] = 0
+				global["@cursor_group"] = "inputA"
 			}
-			dspMap[e.InternalName] = cost
-		}
-		if meta, ok := data["meta"].(map[string]interface{}); ok {
-			meta["dsp_map"] = dspMap
 		}
+	}
 
-		if tone, ok := data["tone"].(map[string]interface{}); ok {
-			// HARDWARE OUTPUTS: Force Multi Output (1) by default instead of Native-Host (15)
-			if dsp0, ok := tone["dsp0"].(map[string]interface{}); ok {
-				if outA, ok := dsp0["outputA"].(map[string]interface{}); ok {
-					outA["@output"] = 1 // Default Multi
-				}
+	// UI DATA: model->DSP costs and the Variax type travel next to the preset, not inside the .hlx
+	view := PresetView{DSPMap: make(map[string]float64), VariaxType: variaxType(hardwareModel), GeneratedBy: model}
+	for _, e := range db.All() {
+		view.DSPMap[e.InternalName] = db.DSPCost(e.InternalName)
+	}
+
+	c.emit(ProgressEvent{Stage: StagePresetEngineer, Kind: ProgressDone, Count: builder.Total()})
+	return &PresetResult{Preset: preset, View: view}, nil
+}
+
+// variaxType returns the Variax family of the hardware model, which has its own bank layout
+func variaxType(hardwareModel string) string {
+	if strings.Contains(strings.ToLower(hardwareModel), "shuriken") {
+		return "shuriken"
+	}
+	return "jtv"
+}
+
+// placeBlocks places the mapped blocks, then applies the rig snapshots to each of them:
+// names, tempos, bypass states and parameter changes (assigned to the snapshot controller)
+func (c *Client) placeBlocks(b *helix.Builder, rig *RigDescription, plans []helix.BlockPlan, defaultExp int, hardware string) error {
+	if rig.Tempo > 0 {
+		b.SetTempo(rig.Tempo)
+	}
+	for s := range rig.Snapshots {
+		if s < helix.SnapshotCount {
+			b.SetSnapshotName(s, rig.Snapshots[s].Name)
+			if rig.Snapshots[s].Tempo > 0 {
+				b.SetSnapshotTempo(s, rig.Snapshots[s].Tempo)
 			}
-			if dsp1, ok := tone["dsp1"].(map[string]interface{}); ok {
-				if outA, ok := dsp1["outputA"].(map[string]interface{}); ok {
-					outA["@output"] = 1 // Default Multi
-				}
+		}
+	}
+
+	for _, plan := range plans {
+		// VIRTUAL BLOCK SKIP: Variax is handled globally via global/snapshot logic
+		if strings.Contains(strings.ToLower(plan.Name), "variax") || strings.Contains(strings.ToLower(plan.ModelName), "variax") {
+			continue
+		}
+
+		block, err := b.AddBlock(plan)
+		if errors.Is(err, helix.ErrUnknownModel) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		c.emit(ProgressEvent{Stage: StagePresetEngineer, Kind: ProgressBlock, Text: plan.Name, Model: block.Entry.Name, Count: b.Total()})
+
+		// TEMPO SYNC: delays and modulations follow the preset tempo with the requested note value
+		if note := noteDivision(rig, plan.Name); note != "" {
+			b.SyncToTempo(block, note)
+		}
+
+		// If block is totally unused, force it enabled in the first snapshot
+		if len(rig.Snapshots) > 0 && !usedInAnySnapshot(rig, plan.Name) {
+			rig.Snapshots[0].ActiveBlocks = append(rig.Snapshots[0].ActiveBlocks, plan.Name)
+		}
+
+		// EXPRESSION: pedals assigned by the Preset Engineer (pedals the hardware lacks are dropped),
+		// else the default pedal for wahs, volumes and whammies
+		assigned := false
+		for _, exp := range plan.Expression {
+			assigned = b.AssignExpression(block, exp, hardware) == nil || assigned
+		}
+		if exp, ok := helix.DefaultExpression(block.Entry, defaultExp, hardware); ok && !assigned {
+			b.AssignExpression(block, exp, hardware)
+		}
+
+		// MIDI CC: parameters driven by incoming CC (reserved CCs are dropped)
+		for _, a := range plan.MIDI {
+			b.AssignMIDICC(block, a)
+		}
+
+		// Default logic (Legacy/No Snapshots): Enable in all 8
+		if len(rig.Snapshots) == 0 {
+			for s := 0; s < helix.SnapshotCount; s++ {
+				b.SetSnapshotBypass(block, s, true)
 			}
+			continue
+		}
 
-			// SERIAL INTERCONNECT: Route DSP0 to Path 2 only if Path 2 is actually used
-			if isDualDSP && path1Count > 0 {
-				if dsp0, ok := tone["dsp0"].(map[string]interface{}); ok {
-					if outA, ok := dsp0["outputA"].(map[string]interface{}); ok {
-						outA["@output"] = 2 // Explicit "Path 2" output for serially linking DSPs
-					}
+		for s := 0; s < helix.SnapshotCount; s++ {
+			// Enable block only if it's in the snapshot's active_blocks
+			if s >= len(rig.Snapshots) {
+				b.SetSnapshotBypass(block, s, false)
+				continue
+			}
+			b.SetSnapshotBypass(block, s, isActiveInSnapshot(rig.Snapshots[s], plan))
+
+			overrides, _ := rig.Snapshots[s].Params[plan.Name].(map[string]interface{})
+			for pName, pVal := range overrides {
+				key := block.Entry.ParamKey(pName)
+				// OPTIMIZATION: Only add a controller if the parameter actually varies across snapshots
+				if !variesAcrossSnapshots(b, block, rig, pName, key) {
+					continue
 				}
+				b.AssignController(block, key, helix.SnapshotController(block.Entry, key))
+				b.SetSnapshotValue(block, s, key, pVal)
 			}
+		}
+	}
+	return nil
+}
 
-			if global, ok := tone["global"].(map[string]interface{}); ok {
-				global["@cursor_dsp"] = 0
-				global["@cursor_group"] = "inputA"
+// noteDivision returns the note division the Sound Engineer asked for a block, if any
+func noteDivision(rig *RigDescription, name string) string {
+	for _, comp := range rig.Chain {
+		if strings.EqualFold(comp.Name, name) {
+			return comp.NoteDivision
+		}
+	}
+	return ""
+}
+
+// usedInAnySnapshot reports whether some snapshot lists the block as active
+func usedInAnySnapshot(rig *RigDescription, name string) bool {
+	bn := strings.ToLower(name)
+	for _, snapshot := range rig.Snapshots {
+		for _, activeName := range snapshot.ActiveBlocks {
+			an := strings.ToLower(activeName)
+			if an == bn || strings.Contains(an, bn) || strings.Contains(bn, an) {
+				return true
 			}
 		}
 	}
+	return false
+}
+
+// isActiveInSnapshot checks the snapshot's active blocks against both the block name and the
+// model name, for robustness
+func isActiveInSnapshot(snapshot Snapshot, plan helix.BlockPlan) bool {
+	bName := strings.ToLower(plan.Name)
+	bModel := strings.ToLower(plan.ModelName)
+	for _, activeName := range snapshot.ActiveBlocks {
+		a := strings.ToLower(activeName)
+		if a == bName || a == bModel || strings.Contains(bName, a) || strings.Contains(a, bName) || strings.Contains(bModel, a) || strings.Contains(a, bModel) {
+			return true
+		}
+	}
+	return false
+}
 
-	return preset, nil
+// variesAcrossSnapshots reports whether some snapshot sets the parameter to a value other than the block's base value
+func variesAcrossSnapshots(b *helix.Builder, block helix.PlacedBlock, rig *RigDescription, pName, key string) bool {
+	baseline, _ := b.Value(block, key)
+	for _, snapshot := range rig.Snapshots {
+		if overrides, ok := snapshot.Params[block.Name].(map[string]interface{}); ok {
+			if v, ok := overrides[pName]; ok {
+				if sanitized, ok := helix.SanitizeParam(b.DB, block.Entry.InternalName, key, v); ok && sanitized != baseline {
+					return true
+				}
+			}
+		}
+	}
+	return false
 }
 
 func applyVariax(preset *helix.Preset, rig *RigDescription, hardwareModel string) {
@@ -828,15 +636,6 @@ func applyVariax(preset *helix.Preset, rig *RigDescription, hardwareModel string
 	}
 	v["@variax_magmode"] = true
 
-	// Expose Variax Type to UI meta
-	if meta, ok := data["meta"].(map[string]interface{}); ok {
-		vType := "jtv"
-		if strings.Contains(strings.ToLower(hardwareModel), "shuriken") {
-			vType = "shuriken"
-		}
-		meta["variax_type"] = vType
-	}
-
 	// 2. Global Tuning Logic
 	offsets, isTuningMapped := getTuningOffsets(rig.Tuning, hardwareModel)
 	if isTuningMapped {
@@ -858,7 +657,7 @@ func applyVariax(preset *helix.Preset, rig *RigDescription, hardwareModel string
 		}
 		if cv, ok := ctrl["variax"].(map[string]interface{}); ok {
 			cv["@variax_model"] = map[string]interface{}{
-				"@controller":       19, // Snapshot Control
+				"@controller":       helix.ControllerSnapshot,
 				"@globalblock":      "inputA",
 				"@globaldsp":        0,
 				"@max":              60,
@@ -926,57 +725,3 @@ func applyVariax(preset *helix.Preset, rig *RigDescription, hardwareModel string
 		}
 	}
 }
-
-func sanitizeParam(internalID, k string, v interface{}) interface{} {
-	val, isFloat := v.(float64)
-	if !isFloat {
-		return v
-	}
-
-	lowK := strings.ToLower(k)
-
-	// List of parameters that should be in 0.0-1.0 range internally (0-10 on knob)
-	isPercentageParam := strings.Contains(lowK, "gain") ||
-		strings.Contains(lowK, "drive") ||
-		strings.Contains(lowK, "bass") ||
-		strings.Contains(lowK, "mid") ||
-		strings.Contains(lowK, "treble") ||
-		strings.Contains(lowK, "presence") ||
-		strings.Contains(lowK, "chvol") ||
-		strings.Contains(lowK, "master") ||
-		strings.Contains(lowK, "level") ||
-		strings.Contains(lowK, "mix") ||
-		strings.Contains(lowK, "feedback") ||
-		strings.Contains(lowK, "fdbk") ||
-		strings.Contains(lowK, "pedal")
-
-	if isPercentageParam {
-		// Normalization: If the AI provides 3.5, it likely meant 0.35
-		if val > 1.0 {
-			val = val / 10.0
-		}
-		// Hard Cap: Ensure we don't exceed 1.0 for these parameters
-		if val > 1.0 {
-			val = 1.0
-		}
-		v = val
-	}
-
-	// Reverb Decay Sanitization (Reverbs and Delays with Reverb tails)
-	isReverb := strings.HasPrefix(internalID, "HD2_Reverb") || strings.HasPrefix(internalID, "VIC_Reverb")
-	isDelay := strings.HasPrefix(internalID, "HD2_Delay") || strings.HasPrefix(internalID, "VIC_Delay")
-	if (isReverb && (lowK == "decay" || lowK == "verbdecay")) || (isDelay && lowK == "verbdecay") {
-		if val >= 0.7 {
-			return 0.7
-		}
-	}
-
-	// Delay Feedback Sanitization
-	if isDelay && (lowK == "feedback" || lowK == "fdbk" || lowK == "bk") {
-		if val >= 0.75 {
-			return 0.75
-		}
-	}
-
-	return v
-}
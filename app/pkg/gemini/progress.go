@@ -0,0 +1,130 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// Agent stages reported in progress events
+const (
+	StageSoundEngineer  = "sound_engineer"
+	StagePresetEngineer = "preset_engineer"
+)
+
+// Progress event kinds
+const (
+	ProgressStarted  = "started"  // The agent call has been sent
+	ProgressPartial  = "partial"  // Text contains the explanation streamed so far
+	ProgressCached   = "cached"   // The response comes from the on-disk cache (Model produced it)
+	ProgressRetry    = "retry"    // The call failed transiently and is retried (Text is the error kind, Count the attempt)
+	ProgressFallback = "fallback" // The model failed, switching to the next fallback model (Model)
+	ProgressBlock    = "block"    // A block has been mapped to a Helix model
+	ProgressDone     = "done"     // The typed result is about to be returned
+)
+
+// ProgressEvent is emitted while an agent is generating
+type ProgressEvent struct {
+	Stage string `json:"stage"`
+	Kind  string `json:"kind"`
+	Text  string `json:"text,omitempty"` // Partial explanation (partial), error kind (retry, fallback) or block name (block)
+	// Model depends on the kind: the Helix model of the mapped block (block), the AI model that produced
+	// the cached response (cached), the AI model being retried (retry) or the AI model tried next (fallback)
+	Model string `json:"model,omitempty"`
+	Count int    `json:"count,omitempty"` // Number of blocks mapped so far (block), attempt number (retry)
+}
+
+// ProgressFunc receives progress events. It must not block.
+type ProgressFunc func(ProgressEvent)
+
+// emit sends a progress event if a listener is set
+func (c *Client) emit(ev ProgressEvent) {
+	if c.OnProgress != nil {
+		c.OnProgress(ev)
+	}
+}
+
+// streamOnce streams a JSON generation and returns the full text.
+// The "explanation" field is reported as partial text while it arrives.
+// received is true once any text has been streamed (the request must not be retried then).
+func (c *Client) streamOnce(ctx context.Context, model string, stage string, contents []*genai.Content, config *genai.GenerateContentConfig) (text string, received bool, err error) {
+	var sb strings.Builder
+	lastExplanation := ""
+	// Usage metadata is cumulative: the last chunk carrying it holds the totals
+	var usage *genai.GenerateContentResponseUsageMetadata
+	defer func() { c.reportUsage(stage, model, usage) }()
+
+	for resp, err := range c.provider.GenerateContentStream(ctx, model, contents, config) {
+		if err != nil {
+			return "", sb.Len() > 0, err
+		}
+		if resp.UsageMetadata != nil {
+			usage = resp.UsageMetadata
+		}
+		if err := safetyError(resp, model); err != nil {
+			return "", sb.Len() > 0, err
+		}
+		if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+			continue
+		}
+		for _, part := range resp.Candidates[0].Content.Parts {
+			sb.WriteString(part.Text)
+		}
+		if explanation := partialJSONString(sb.String(), "explanation"); explanation != lastExplanation {
+			lastExplanation = explanation
+			c.emit(ProgressEvent{Stage: stage, Kind: ProgressPartial, Text: explanation})
+		}
+	}
+	return sb.String(), sb.Len() > 0, nil
+}
+
+// partialJSONString extracts the (possibly unterminated) string value of a top-level key from incomplete JSON
+func partialJSONString(text, key string) string {
+	marker := `"` + key + `"`
+	idx := strings.Index(text, marker)
+	if idx < 0 {
+		return ""
+	}
+	rest := strings.TrimLeft(text[idx+len(marker):], " \t\r\n")
+	if !strings.HasPrefix(rest, ":") {
+		return ""
+	}
+	rest = strings.TrimLeft(rest[1:], " \t\r\n")
+	if !strings.HasPrefix(rest, `"`) {
+		return ""
+	}
+
+	// Find the closing quote, or close the string ourselves if it is still streaming
+	raw := ""
+	for i := 1; i < len(rest); i++ {
+		switch rest[i] {
+		case '\\':
+			size := 2
+			if i+1 < len(rest) && rest[i+1] == 'u' {
+				size = 6
+			}
+			if i+size > len(rest) {
+				raw = rest[:i] + `"` // Drop the incomplete escape sequence
+			}
+			i += size - 1
+		case '"':
+			raw = rest[:i+1]
+		default:
+			continue
+		}
+		if raw != "" {
+			break
+		}
+	}
+	if raw == "" {
+		raw = rest + `"`
+	}
+
+	var s string
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,29 @@
+package gemini
+
+import "testing"
+
+func TestPartialJSONString(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"Key Not Yet Received", `{"suggested_name": "Plexi`, ""},
+		{"Value Not Started", `{"explanation": `, ""},
+		{"Streaming Value", `{"explanation": "A warm crunch`, "A warm crunch"},
+		{"Complete Value", `{"explanation": "Done.", "chain": [`, "Done."},
+		{"Escaped Quote", `{"explanation": "The \"brown\" sound`, `The "brown" sound`},
+		{"Escaped Backslash At End", `{"explanation": "C:\\`, `C:\`},
+		{"Dangling Escape", `{"explanation": "Line one\`, "Line one"},
+		{"Dangling Unicode", `{"explanation": "Caf\u00e`, "Caf"},
+		{"Not A String", `{"explanation": null}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := partialJSONString(tt.text, "explanation"); got != tt.want {
+				t.Errorf("partialJSONString(%s) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// Provider streams content generations. genai.Models implements it; tests use a ReplayProvider.
+type Provider interface {
+	GenerateContentStream(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error]
+}
+
+// Fixture is a recorded sequence of provider calls
+type Fixture struct {
+	Calls []FixtureCall `json:"calls"`
+}
+
+// FixtureCall is one recorded provider call: either a response text or an API error
+type FixtureCall struct {
+	Model          string             `json:"model"`
+	RequestHash    string             `json:"request_hash,omitempty"` // CacheKey of the request, checked in strict mode
+	Response       string             `json:"response,omitempty"`
+	Error          *genai.APIError    `json:"error,omitempty"`
+	PromptTokens   int                `json:"prompt_tokens,omitempty"`
+	ResponseTokens int                `json:"response_tokens,omitempty"`
+	FinishReason   genai.FinishReason `json:"finish_reason,omitempty"`
+}
+
+// LoadFixture reads a fixture file
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %v", path, err)
+	}
+	return &f, nil
+}
+
+// Save writes the fixture file
+func (f *Fixture) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// replayChunkSize is the size of the streamed chunks, small enough to exercise partial progress events
+const replayChunkSize = 64
+
+// ReplayProvider answers calls from a fixture, in order, without any network access
+type ReplayProvider struct {
+	Fixture *Fixture
+	Strict  bool // Fail when the model or the request hash differ from the recording (the prompt changed)
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewReplayProvider replays the calls of a fixture
+func NewReplayProvider(f *Fixture) *ReplayProvider {
+	return &ReplayProvider{Fixture: f}
+}
+
+// Remaining returns the number of recorded calls not replayed yet
+func (p *ReplayProvider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.Fixture.Calls) - p.next
+}
+
+// nextCall pops the next recorded call, checking it matches the request in strict mode
+func (p *ReplayProvider) nextCall(model string, contents []*genai.Content, config *genai.GenerateContentConfig) (FixtureCall, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next >= len(p.Fixture.Calls) {
+		return FixtureCall{}, fmt.Errorf("replay: no recorded call left (%d replayed)", p.next)
+	}
+	call := p.Fixture.Calls[p.next]
+	p.next++
+	if p.Strict {
+		if call.Model != "" && call.Model != model {
+			return call, fmt.Errorf("replay: call %d was recorded with model %s, got %s", p.next, call.Model, model)
+		}
+		if call.RequestHash != "" && call.RequestHash != CacheKey(model, contents, config) {
+			return call, fmt.Errorf("replay: call %d was recorded for a different request (prompt changed, re-record the fixture)", p.next)
+		}
+	}
+	return call, nil
+}
+
+func (p *ReplayProvider) GenerateContentStream(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		call, err := p.nextCall(model, contents, config)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if call.Error != nil {
+			yield(nil, *call.Error)
+			return
+		}
+
+		text := call.Response
+		for start := 0; start < len(text) || start == 0; start += replayChunkSize {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			end := min(start+replayChunkSize, len(text))
+			resp := &genai.GenerateContentResponse{
+				Candidates: []*genai.Candidate{{
+					Content: &genai.Content{Role: "model", Parts: []*genai.Part{{Text: text[start:end]}}},
+				}},
+			}
+			if end == len(text) {
+				resp.Candidates[0].FinishReason = call.FinishReason
+				resp.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{
+					PromptTokenCount:     int32(call.PromptTokens),
+					CandidatesTokenCount: int32(call.ResponseTokens),
+				}
+			}
+			if !yield(resp, nil) || end == len(text) {
+				return
+			}
+		}
+	}
+}
+
+// RecordingProvider forwards calls to a real provider and records them into a fixture
+type RecordingProvider struct {
+	Provider Provider
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// Fixture returns the calls recorded so far
+func (r *RecordingProvider) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := Fixture{Calls: append([]FixtureCall(nil), r.fixture.Calls...)}
+	return &f
+}
+
+func (r *RecordingProvider) GenerateContentStream(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		call := FixtureCall{Model: model, RequestHash: CacheKey(model, contents, config)}
+		var sb strings.Builder
+		defer func() {
+			call.Response = sb.String()
+			r.mu.Lock()
+			r.fixture.Calls = append(r.fixture.Calls, call)
+			r.mu.Unlock()
+		}()
+
+		for resp, err := range r.Provider.GenerateContentStream(ctx, model, contents, config) {
+			if err != nil {
+				if apiErr, ok := err.(genai.APIError); ok {
+					call.Error = &apiErr
+				}
+				yield(resp, err)
+				return
+			}
+			if len(resp.Candidates) > 0 && resp.Candidates[0].Content != nil {
+				for _, part := range resp.Candidates[0].Content.Parts {
+					sb.WriteString(part.Text)
+				}
+				if resp.Candidates[0].FinishReason != "" && resp.Candidates[0].FinishReason != genai.FinishReasonStop {
+					call.FinishReason = resp.Candidates[0].FinishReason
+				}
+			}
+			if resp.UsageMetadata != nil {
+				call.PromptTokens = int(resp.UsageMetadata.PromptTokenCount)
+				call.ResponseTokens = int(resp.UsageMetadata.CandidatesTokenCount + resp.UsageMetadata.ThoughtsTokenCount)
+			}
+			if !yield(resp, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// RetryPolicy controls how transient provider errors (429, 5xx) are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts, including the first one
+	BaseDelay   time.Duration // Delay before the first retry, doubled on each attempt
+	MaxDelay    time.Duration // Upper bound of a single wait. A longer Retry-After gives up instead.
+}
+
+// DefaultRetryPolicy suits free-tier keys: a handful of retries within about a minute
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+}
+
+// generateStream returns the cached response if any, otherwise streams a JSON generation, retrying transient errors with exponential backoff and jitter,
+// then moving down the fallback chain. It returns the text and the model that produced it.
+// An empty string means the model returned nothing.
+// A generated response is not cached here: the caller passes the returned cache key to storeResponse once it has parsed the text.
+// The key is empty for a response served from the cache.
+func (c *Client) generateStream(ctx context.Context, stage string, contents []*genai.Content, config *genai.GenerateContentConfig) (text, model, cacheKey string, err error) {
+	c.emit(ProgressEvent{Stage: stage, Kind: ProgressStarted})
+
+	if c.Cache != nil {
+		cacheKey = CacheKey(c.ModelName, contents, config)
+		if !c.BypassCache {
+			if text, model, ok := c.Cache.Get(cacheKey); ok {
+				c.emit(ProgressEvent{Stage: stage, Kind: ProgressCached, Model: model})
+				if explanation := partialJSONString(text, "explanation"); explanation != "" {
+					c.emit(ProgressEvent{Stage: stage, Kind: ProgressPartial, Text: explanation})
+				}
+				return text, model, "", nil
+			}
+		}
+	}
+
+	models := c.modelChain()
+	for i, model := range models {
+		text, received, err := c.generateWithRetry(ctx, model, stage, contents, config)
+		if err == nil {
+			return text, model, cacheKey, nil
+		}
+		var gerr *Error
+		if received || i == len(models)-1 || !errors.As(err, &gerr) || !gerr.FallbackEligible() {
+			return "", model, "", err
+		}
+		c.emit(ProgressEvent{Stage: stage, Kind: ProgressFallback, Text: string(gerr.Kind), Model: models[i+1]})
+	}
+	return "", "", "", errors.New("no model configured")
+}
+
+// storeResponse caches a response the caller has parsed successfully.
+// A bypassed request still refreshes the cached response.
+func (c *Client) storeResponse(cacheKey, model, text string) {
+	if c.Cache == nil || cacheKey == "" || text == "" {
+		return
+	}
+	if err := c.Cache.Put(cacheKey, model, text); err != nil && c.OnCacheError != nil {
+		c.OnCacheError(err)
+	}
+}
+
+// modelChain returns the configured model followed by the fallbacks, without blanks or duplicates
+func (c *Client) modelChain() []string {
+	var chain []string
+	seen := make(map[string]bool)
+	for _, m := range append([]string{c.ModelName}, c.Fallbacks...) {
+		m = strings.TrimPrefix(strings.TrimSpace(m), "models/")
+		if m == "" || seen[m] {
+			continue
+		}
+		seen[m] = true
+		chain = append(chain, m)
+	}
+	return chain
+}
+
+// generateWithRetry streams a generation from one model, retrying transient errors.
+// received is true if partial output was streamed before the failure.
+func (c *Client) generateWithRetry(ctx context.Context, model string, stage string, contents []*genai.Content, config *genai.GenerateContentConfig) (string, bool, error) {
+	for attempt := 1; ; attempt++ {
+		text, received, err := c.streamOnce(ctx, model, stage, contents, config)
+		if err == nil {
+			return text, received, nil
+		}
+
+		err = classifyError(err, model)
+		var gerr *Error
+		if !errors.As(err, &gerr) {
+			return "", received, err
+		}
+		if gerr.RetryAfter == 0 && c.transport != nil {
+			gerr.RetryAfter = c.transport.lastRetryAfter()
+		}
+		// Partial output already reached the UI: don't replay it
+		if received || !gerr.Retryable() || attempt >= c.Retry.MaxAttempts {
+			return "", received, gerr
+		}
+
+		delay, ok := c.Retry.backoff(attempt, gerr.RetryAfter)
+		if !ok {
+			return "", received, gerr
+		}
+		c.emit(ProgressEvent{Stage: stage, Kind: ProgressRetry, Text: string(gerr.Kind), Model: model, Count: attempt})
+		select {
+		case <-ctx.Done():
+			return "", received, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff returns the wait before retry number attempt (1-based).
+// The provider's Retry-After wins over the exponential delay; false means it exceeds MaxDelay.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) (time.Duration, bool) {
+	if retryAfter > 0 {
+		return retryAfter, retryAfter <= p.MaxDelay
+	}
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	// Full jitter on the upper half to avoid synchronized retries
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)), true
+}
@@ -74,8 +74,6 @@ func TestSnapshotMapping(t *testing.T) {
 		}
 	})
 
-	// To test parameter shifts, we'd need to simulate the ChatPresetEngineer loop.
-	// Since that's hard to isolate without refactoring, I've manually verified
-	// the logic in the code. I will assume the code implementation is correct
-	// based on the logic audit.
+	// Parameter shifts through the full ChatPresetEngineer loop are covered
+	// by the replay golden tests (engineers_test.go).
 }
@@ -1,28 +1,53 @@
 package gemini
 
 import (
+	"HelAIx/pkg/helix"
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"google.golang.org/genai"
 )
 
 // RigDescription is the output of the Designer Agent
 type RigDescription struct {
-	SuggestedName string         `json:"suggested_name"` // Concise name for the preset
-	Explanation   string         `json:"explanation"`    // Textual description of the design
-	GuitarModel   string         `json:"guitar_model"`   // Variax model suggestion (Lester, Spank, T-Model, Acoustic, etc)
-	Tuning        string         `json:"tuning"`         // Tuning suggestion (Standard, Drop D, Half Step Down, etc)
-	Chain         []RigComponent `json:"chain"`
-	Snapshots     []Snapshot     `json:"snapshots,omitempty"`
+	SuggestedName string             `json:"suggested_name"` // Concise name for the preset
+	Explanation   string             `json:"explanation"`    // Textual description of the design
+	GuitarModel   string             `json:"guitar_model"`   // Variax model suggestion (Lester, Spank, T-Model, Acoustic, etc)
+	Tuning        string             `json:"tuning"`         // Tuning suggestion (Standard, Drop D, Half Step Down, etc)
+	Chain         []RigComponent     `json:"chain"`
+	Snapshots     []Snapshot         `json:"snapshots,omitempty"`
+	Tempo         float64            `json:"tempo,omitempty"`        // Song BPM, 0 = keep the default tempo
+	Input         *helix.InputConfig `json:"input,omitempty"`        // Input noise gate and Guitar In-Z, nil = automatic
+	Genre         string             `json:"genre,omitempty"`        // Musical genre, e.g. "Blues Rock"
+	Tags          []string           `json:"tags,omitempty"`         // Search keywords: artist, song, era, sound
+	Prompt        string             `json:"prompt,omitempty"`       // Original request of the user
+	GeneratedBy   string             `json:"generated_by,omitempty"` // Model that actually answered (may be a fallback)
+}
+
+// Summary describes the rig in one line: its signal chain, snapshots and tempo
+func (r *RigDescription) Summary() string {
+	var names []string
+	for _, comp := range r.Chain {
+		names = append(names, comp.Name)
+	}
+	summary := strings.Join(names, " → ")
+	if len(r.Snapshots) > 0 {
+		summary += fmt.Sprintf(" (%d snapshots)", len(r.Snapshots))
+	}
+	if r.Tempo > 0 {
+		summary += fmt.Sprintf(" @ %g BPM", r.Tempo)
+	}
+	return summary
 }
 
 type RigComponent struct {
-	Type        string `json:"type"`        // amp, cab, pedal, modulation, delay, reverb
-	Name        string `json:"name"`        // Real world name, e.g. "Tube Screamer"
-	Description string `json:"description"` // Brief motivation, e.g. "For mid boost"
-	Settings    string `json:"settings"`    // Abstract settings, e.g. "High gain, low mids"
+	Type         string `json:"type"`                    // amp, cab, pedal, modulation, delay, reverb
+	Name         string `json:"name"`                    // Real world name, e.g. "Tube Screamer"
+	Description  string `json:"description"`             // Brief motivation, e.g. "For mid boost"
+	Settings     string `json:"settings"`                // Abstract settings, e.g. "High gain, low mids"
+	NoteDivision string `json:"note_division,omitempty"` // Tempo-synced delays and modulations, e.g. "1/8." (dotted eighth)
 }
 
 type Snapshot struct {
@@ -31,6 +56,7 @@ type Snapshot struct {
 	GuitarModel  string                 `json:"guitar_model,omitempty"`
 	Tuning       string                 `json:"tuning,omitempty"`
 	Params       map[string]interface{} `json:"params,omitempty"` // BlockName -> { "Param": Value }
+	Tempo        float64                `json:"tempo,omitempty"`  // Overrides the preset tempo (BPM)
 }
 
 // ChatSoundEngineer creates or refines the abstract sound design based on discussion history
@@ -54,18 +80,26 @@ func (c *Client) ChatSoundEngineer(ctx context.Context, history []ChatMessage, h
 	4. "tuning": A specific tuning required (e.g. "Standard"). Global default for the preset.
 	5. "chain": An array of components representing the ENTIRE signal chain.
 	6. "snapshots": (Conditional) An array of 1 to 4 snapshot objects if a song/artist is requested or explicitly asked for.
+	7. "tempo": (Optional) The song tempo in BPM (e.g. 128) when a song is requested or the user gives a tempo.
+	8. "input": (Optional) The guitar input settings: {"noise_gate": true, "threshold": -56, "decay": 0.1, "impedance": "1M"}.
+	   "threshold" is in dB (-96 to 0, around -56 for high gain), "decay" in seconds. "impedance" is the Guitar In-Z, one of Auto, 1M, 3.5M, 230k, 136k, 90k, 70k, 32k, 22k, 10k (lower values mimic a fuzz or a wah first in the chain).
+	   Omit it to let the gate follow the amount of gain.
+	9. "genre": The musical genre of the sound (e.g. "Blues Rock", "Ambient", "Thrash Metal").
+	10. "tags": 3 to 6 lowercase search keywords (artist, song, era, character), e.g. ["u2", "the edge", "80s", "clean", "delay"].
 	
 	Each "chain" item should have:
 	- "type": one of [pedal, amp, cab, modulation, delay, reverb, variax]
 	- "name": The SPECIFIC REAL-WORLD model name of the gear (e.g. "Ibanez Tube Screamer"). For variax, use "Line6 Variax".
 	- "description": Why you chose this or how it fits.
 	- "settings": A brief text description of how to dial it in (e.g. "Lester model, Standard tuning").
+	- "note_division": (Optional, delay and modulation only) The note value the block follows when it must be in time with the song: "1/4", "1/8", "1/8." (dotted eighth), "1/4T" (quarter triplet), ...
 
 	Each "snapshot" item should have:
 	- "name": Concise part name (e.g. "Intro", "Chorus", "Solo", "Clean", "Lead").
 	- "active_blocks": An array of "name" strings from the "chain" that should be ENABLED in this snapshot. Others will be DISABLED.
 	- "guitar_model": (Optional) Override the global guitar model for this snapshot.
 	- "tuning": (Optional) Override the global tuning for this snapshot.
+	- "tempo": (Optional) Override the song tempo for this snapshot (e.g. a half-time bridge).
 	- "params": (Optional) A map where keys are block names and values are objects of parameter overrides (e.g. {"Marshall Plexi": {"Drive": 0.8, "Master": 1.0}}). Only specify what MUST change compared to the baseline.
 
 	SNAPSHOT LOGIC:
@@ -131,21 +165,30 @@ func (c *Client) ChatSoundEngineer(ctx context.Context, history []ChatMessage, h
 		ResponseMIMEType: "application/json",
 	}
 
-	resp, err := c.client.Models.GenerateContent(ctx, c.ModelName, contents, config)
+	jsonText, model, cacheKey, err := c.generateStream(ctx, StageSoundEngineer, contents, config)
 	if err != nil {
-		return nil, fmt.Errorf("sound engineer agent failed: %v", err)
+		return nil, fmt.Errorf("sound engineer agent failed: %w", err)
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
+	if jsonText == "" {
 		return nil, fmt.Errorf("empty response from Sound Engineer Agent")
 	}
 
-	jsonText := resp.Candidates[0].Content.Parts[0].Text
-
 	var result RigDescription
 	if err := json.Unmarshal([]byte(jsonText), &result); err != nil {
 		return nil, fmt.Errorf("failed to parse Sound Engineer JSON: %v. Raw: %s", err, jsonText)
 	}
+	c.storeResponse(cacheKey, model, jsonText)
+
+	result.GeneratedBy = model
+	// PROVENANCE: the first user message is the original request
+	for _, msg := range history {
+		if msg.Role == "user" {
+			result.Prompt = msg.Content
+			break
+		}
+	}
 
+	c.emit(ProgressEvent{Stage: StageSoundEngineer, Kind: ProgressDone, Text: result.Explanation, Count: len(result.Chain)})
 	return &result, nil
 }
@@ -0,0 +1,31 @@
+package gemini
+
+import "google.golang.org/genai"
+
+// Usage is the token count of one provider call
+type Usage struct {
+	Stage          string `json:"stage"`
+	Model          string `json:"model"`
+	PromptTokens   int    `json:"prompt_tokens"`
+	ResponseTokens int    `json:"response_tokens"` // Candidates + thinking tokens (both billed as output)
+}
+
+// UsageFunc receives the token usage of every provider call, including failed attempts that were billed
+type UsageFunc func(Usage)
+
+// reportUsage forwards the usage metadata of a call if a listener is set
+func (c *Client) reportUsage(stage, model string, meta *genai.GenerateContentResponseUsageMetadata) {
+	if c.OnUsage == nil || meta == nil {
+		return
+	}
+	u := Usage{
+		Stage:          stage,
+		Model:          model,
+		PromptTokens:   int(meta.PromptTokenCount),
+		ResponseTokens: int(meta.CandidatesTokenCount + meta.ThoughtsTokenCount),
+	}
+	if u.PromptTokens == 0 && u.ResponseTokens == 0 {
+		return
+	}
+	c.OnUsage(u)
+}
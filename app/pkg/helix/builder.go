@@ -0,0 +1,300 @@
+package helix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Controller IDs of the .hlx format
+const (
+	ControllerNone     = 0
+	ControllerExp1     = 1
+	ControllerExp2     = 2
+	ControllerExp3     = 3
+	ControllerSnapshot = 19 // Value recalled per snapshot (as written by HX Edit)
+
+	// controllerSnapshotLegacy is the snapshot ID written by older HelAIx builds; it is still read as ControllerSnapshot
+	controllerSnapshotLegacy = 9
+)
+
+// isSnapshotController reports whether a controller ID recalls the value per snapshot
+func isSnapshotController(id int) bool {
+	return id == ControllerSnapshot || id == controllerSnapshotLegacy
+}
+
+// Output IDs of the path outputs ("outputA" "@output")
+const (
+	OutputMulti = 1 // Multi (1/4", XLR, digital)
+	OutputPath2 = 2 // Serially feeds Path 2 (dsp1)
+)
+
+// SnapshotCount is the number of snapshots of a preset
+const SnapshotCount = 8
+
+// ErrUnknownModel is returned by AddBlock when the model exists in no catalog at all
+var ErrUnknownModel = errors.New("unknown model")
+
+// BlockPlan is a block to place: which model, on which path, with which parameters.
+// Params use the names given by the AI or the user; they are matched to the model's technical keys.
+type BlockPlan struct {
+	Name       string                 `json:"name"`       // Display name ("@name"), e.g. the Sound Engineer component
+	ModelName  string                 `json:"model_name"` // Display name or internal ID of the model
+	Path       int                    `json:"path"`       // DSP: 0 (Path 1) or 1 (Path 2)
+	Params     map[string]interface{} `json:"params"`
+	Expression []Expression           `json:"expression,omitempty"` // Expression pedal assignments
+	MIDI       []MIDIAssignment       `json:"midi_cc,omitempty"`    // Incoming MIDI CC assignments
+}
+
+// PlacedBlock locates a block placed by the Builder
+type PlacedBlock struct {
+	Name  string
+	Entry CatalogEntry
+	Path  int    // DSP index
+	Key   string // e.g. "block3"
+}
+
+// Controller is the assignment of a block parameter to a controller
+type Controller struct {
+	ID  int
+	Min float64
+	Max float64
+}
+
+// SnapshotController returns the snapshot assignment of a parameter, spanning its catalog range
+func SnapshotController(entry CatalogEntry, param string) Controller {
+	c := Controller{ID: ControllerSnapshot, Min: 0, Max: 1}
+	if p, ok := entry.Param(param); ok {
+		c.Min, c.Max = p.Min, p.Max
+	}
+	return c
+}
+
+// Builder places blocks, snapshot states, controllers and outputs in a preset.
+// It only edits the preset map: no prompting, parsing or catalog context.
+type Builder struct {
+	Preset  *Preset
+	DB      *CatalogDB
+	DualDSP bool // False forces every block on Path 1 (HX Stomp, Native single path)
+
+	counts [2]int
+}
+
+// NewBuilder returns a builder for a preset (usually a NewTemplatePreset) and the catalog of its firmware
+func NewBuilder(p *Preset, db *CatalogDB, dualDSP bool) *Builder {
+	db.EnsureLoaded()
+	return &Builder{Preset: p, DB: db, DualDSP: dualDSP}
+}
+
+// Count returns the number of blocks placed on a path
+func (b *Builder) Count(path int) int {
+	return b.counts[path]
+}
+
+// Total returns the number of blocks placed on both paths
+func (b *Builder) Total() int {
+	return b.counts[0] + b.counts[1]
+}
+
+// AddBlock places a block at the next position of its path, starting from the model defaults.
+// Models missing from the firmware are replaced by their closest substitute.
+func (b *Builder) AddBlock(plan BlockPlan) (PlacedBlock, error) {
+	entry, found := b.DB.Resolve(plan.ModelName)
+	if !found {
+		// COMPATIBILITY: Model from a newer firmware, substitute the closest available one
+		newer, known := DB.Resolve(plan.ModelName)
+		if !known {
+			return PlacedBlock{}, fmt.Errorf("%w: %s", ErrUnknownModel, plan.ModelName)
+		}
+		entry, found = b.DB.Substitute(newer)
+		if !found {
+			return PlacedBlock{}, fmt.Errorf("model %q is not available on firmware %s and has no substitute", plan.ModelName, b.DB.Firmware)
+		}
+	}
+	defaults, _ := entry.Data["Defaults"].(map[string]interface{})
+
+	path := plan.Path
+	if !b.DualDSP || path != 1 {
+		path = 0
+	}
+	dsp := b.section(fmt.Sprintf("dsp%d", path))
+	if dsp == nil {
+		return PlacedBlock{}, fmt.Errorf("preset has no dsp%d", path)
+	}
+
+	pos := b.counts[path]
+	b.counts[path]++
+	block := PlacedBlock{Name: plan.Name, Entry: entry, Path: path, Key: fmt.Sprintf("block%d", pos)}
+
+	params := make(map[string]interface{})
+	for k, v := range defaults {
+		params[k] = v
+	}
+	params["@name"] = plan.Name
+	params["@model"] = entry.InternalName
+	params["@enabled"] = true
+	params["@position"] = pos
+	params["@type"] = entry.BlockType()
+	for name, v := range plan.Params {
+		key := entry.ParamKey(name)
+		if v, ok := SanitizeParam(b.DB, entry.InternalName, key, v); ok {
+			params[key] = v
+		}
+	}
+	// ENSURE ROUTING: Sub-path (A/B) is locked to A to avoid unnecessary split blocks
+	params["@path"] = 0
+
+	dsp[block.Key] = params
+	return block, nil
+}
+
+// ParamKey maps a generic parameter name ("Gain", "Volume", "mids") to the technical key of the model.
+// Unknown names are returned unchanged.
+func (e CatalogEntry) ParamKey(name string) string {
+	defaults, _ := e.Data["Defaults"].(map[string]interface{})
+	if _, ok := defaults[name]; ok {
+		return name
+	}
+
+	var alternatives []string
+	switch strings.ToLower(name) {
+	case "gain":
+		alternatives = []string{"Drive", "LeadGain", "Lead Drive", "ChVol", "Master"}
+	case "drive":
+		alternatives = []string{"Gain", "LeadDrive", "Lead Gain", "Overdrive"}
+	case "volume", "vol":
+		alternatives = []string{"ChVol", "Master", "Level"}
+	case "mids":
+		alternatives = []string{"Middle", "Mid"}
+	}
+	for _, alt := range alternatives {
+		if _, ok := defaults[alt]; ok {
+			return alt
+		}
+	}
+
+	for key := range defaults {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+// Value returns the current value of a block parameter
+func (b *Builder) Value(block PlacedBlock, param string) (interface{}, bool) {
+	v, ok := b.block(block)[param]
+	return v, ok
+}
+
+// SetSnapshotName renames a snapshot
+func (b *Builder) SetSnapshotName(snapshot int, name string) {
+	if snap := b.section(fmt.Sprintf("snapshot%d", snapshot)); snap != nil {
+		snap["@name"] = name
+		snap["@custom_name"] = true
+	}
+}
+
+// SetSnapshotBypass sets the on/off state of a block in a snapshot.
+// Snapshot 0 is the state loaded with the preset, so it is mirrored on the block itself.
+func (b *Builder) SetSnapshotBypass(block PlacedBlock, snapshot int, enabled bool) {
+	snap := b.section(fmt.Sprintf("snapshot%d", snapshot))
+	if snap == nil {
+		return
+	}
+	if dsp := childMap(snap, "blocks", fmt.Sprintf("dsp%d", block.Path)); dsp != nil {
+		dsp[block.Key] = enabled
+	}
+	if snapshot == 0 {
+		if params := b.block(block); params != nil {
+			params["@enabled"] = enabled
+		}
+	}
+}
+
+// AssignController assigns a block parameter to a controller (expression pedal, snapshots, ...).
+// The min/max go through the same safety rules as the parameter itself.
+func (b *Builder) AssignController(block PlacedBlock, param string, c Controller) {
+	ctrls := b.section("controller")
+	if ctrls == nil {
+		return
+	}
+	hi, okMax := b.sanitizeBound(block.Entry, param, c.Max)
+	lo, okMin := b.sanitizeBound(block.Entry, param, c.Min)
+	if !okMax || !okMin {
+		return
+	}
+	if bCtrls := childMap(ctrls, fmt.Sprintf("dsp%d", block.Path), block.Key); bCtrls != nil {
+		bCtrls[param] = map[string]interface{}{
+			"@controller":       c.ID,
+			"@max":              hi,
+			"@min":              lo,
+			"@snapshot_disable": false,
+		}
+	}
+}
+
+// SetSnapshotValue sets the value recalled by a snapshot for a parameter assigned to ControllerSnapshot
+func (b *Builder) SetSnapshotValue(block PlacedBlock, snapshot int, param string, value interface{}) {
+	snap := b.section(fmt.Sprintf("snapshot%d", snapshot))
+	if snap == nil {
+		return
+	}
+	value, ok := SanitizeParam(b.DB, block.Entry.InternalName, param, value)
+	if !ok {
+		return
+	}
+	if sBlock := childMap(snap, "controllers", fmt.Sprintf("dsp%d", block.Path), block.Key); sBlock != nil {
+		sBlock[param] = map[string]interface{}{
+			"@value": value,
+		}
+	}
+}
+
+// sanitizeBound applies SanitizeParam to a controller bound, keeping it numeric.
+// ok is false if the parameter must not be written.
+func (b *Builder) sanitizeBound(entry CatalogEntry, param string, v float64) (float64, bool) {
+	s, ok := SanitizeParam(b.DB, entry.InternalName, param, v)
+	if f, isFloat := s.(float64); isFloat {
+		return f, ok
+	}
+	return v, ok
+}
+
+// SetOutput routes the main output of a path (OutputMulti, OutputPath2, ...)
+func (b *Builder) SetOutput(path int, output int) {
+	if out := nestedMap(b.section(fmt.Sprintf("dsp%d", path)), "outputA"); out != nil {
+		out["@output"] = output
+	}
+}
+
+// section returns a map of the data.tone section of the preset
+func (b *Builder) section(key string) map[string]interface{} {
+	tone, ok := b.Preset.tone()
+	if !ok {
+		return nil
+	}
+	m, _ := tone[key].(map[string]interface{})
+	return m
+}
+
+// block returns the parameters of a placed block
+func (b *Builder) block(block PlacedBlock) map[string]interface{} {
+	return nestedMap(b.section(fmt.Sprintf("dsp%d", block.Path)), block.Key)
+}
+
+// childMap walks a chain of map keys, creating the missing levels
+func childMap(m map[string]interface{}, keys ...string) map[string]interface{} {
+	for _, k := range keys {
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			if _, exists := m[k]; exists {
+				return nil
+			}
+			next = make(map[string]interface{})
+			m[k] = next
+		}
+		m = next
+	}
+	return m
+}
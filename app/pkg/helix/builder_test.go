@@ -0,0 +1,133 @@
+package helix
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestBuilder(t *testing.T, dualDSP bool) *Builder {
+	t.Helper()
+	p, err := NewTemplatePreset("Builder Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewBuilder(p, &DB, dualDSP)
+}
+
+func TestBuilderAddBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		dualDSP  bool
+		plans    []BlockPlan
+		wantPath int
+		wantKey  string
+	}{
+		{"First Block", true, []BlockPlan{{Name: "Drive", ModelName: "Scream 808"}}, 0, "block0"},
+		{"Positions Per Path", true, []BlockPlan{{Name: "A", ModelName: "Scream 808"}, {Name: "B", ModelName: "Scream 808", Path: 1}, {Name: "C", ModelName: "Scream 808", Path: 1}}, 1, "block1"},
+		{"Single DSP Forces Path 1", false, []BlockPlan{{Name: "A", ModelName: "Scream 808", Path: 1}}, 0, "block0"},
+		{"Internal ID", true, []BlockPlan{{Name: "Amp", ModelName: "HD2_AmpBritPlexiBrt"}}, 0, "block0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBuilder(t, tt.dualDSP)
+			var last PlacedBlock
+			for _, plan := range tt.plans {
+				var err error
+				if last, err = b.AddBlock(plan); err != nil {
+					t.Fatalf("AddBlock() error: %v", err)
+				}
+			}
+			if last.Path != tt.wantPath || last.Key != tt.wantKey {
+				t.Errorf("placed at dsp%d/%s, want dsp%d/%s", last.Path, last.Key, tt.wantPath, tt.wantKey)
+			}
+			if b.Total() != len(tt.plans) {
+				t.Errorf("Total() = %d, want %d", b.Total(), len(tt.plans))
+			}
+			if v, _ := b.Value(last, "@enabled"); v != true {
+				t.Errorf("@enabled = %v, want true", v)
+			}
+		})
+	}
+
+	t.Run("Unknown Model", func(t *testing.T) {
+		b := newTestBuilder(t, true)
+		if _, err := b.AddBlock(BlockPlan{Name: "X", ModelName: "Not A Model"}); !errors.Is(err, ErrUnknownModel) {
+			t.Errorf("AddBlock() error = %v, want ErrUnknownModel", err)
+		}
+		if b.Total() != 0 {
+			t.Errorf("unknown model was counted")
+		}
+	})
+
+	t.Run("Param Aliases", func(t *testing.T) {
+		b := newTestBuilder(t, true)
+		block, err := b.AddBlock(BlockPlan{Name: "Amp", ModelName: "Brit Plexi Brt", Params: map[string]interface{}{
+			"Gain": 7.0, "mids": 0.4, "treble": 0.6,
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for key, want := range map[string]interface{}{"Drive": 0.7, "Mid": 0.4, "Treble": 0.6} {
+			if got, _ := b.Value(block, key); got != want {
+				t.Errorf("%s = %v, want %v", key, got, want)
+			}
+		}
+	})
+}
+
+func TestBuilderSnapshotsAndControllers(t *testing.T) {
+	b := newTestBuilder(t, true)
+	b.AddBlock(BlockPlan{Name: "Drive", ModelName: "Scream 808"})
+	block, err := b.AddBlock(BlockPlan{Name: "Drive 2", ModelName: "Scream 808", Path: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tone, _ := b.Preset.tone()
+
+	t.Run("Snapshot 0 Mirrors Block State", func(t *testing.T) {
+		b.SetSnapshotBypass(block, 0, false)
+		b.SetSnapshotBypass(block, 1, true)
+		if v, _ := b.Value(block, "@enabled"); v != false {
+			t.Errorf("@enabled = %v, want false", v)
+		}
+		// The template has no snapshot map for dsp1: it is created
+		if got := nestedMap(tone, "snapshot1", "blocks", "dsp1")["block0"]; got != true {
+			t.Errorf("snapshot1 dsp1/block0 = %v, want true", got)
+		}
+	})
+
+	t.Run("Snapshot Controller", func(t *testing.T) {
+		b.AssignController(block, "Gain", Controller{ID: ControllerSnapshot, Min: 0, Max: 1})
+		b.SetSnapshotValue(block, 2, "Gain", 0.8)
+		ctrl := nestedMap(tone, "controller", "dsp1", "block0", "Gain")
+		if ctrl["@controller"] != ControllerSnapshot {
+			t.Errorf("controller = %v", ctrl)
+		}
+		if v := nestedMap(tone, "snapshot2", "controllers", "dsp1", "block0", "Gain")["@value"]; v != 0.8 {
+			t.Errorf("snapshot2 value = %v, want 0.8", v)
+		}
+	})
+
+	t.Run("Safety Rules On Snapshot Values And Controller Bounds", func(t *testing.T) {
+		delay, err := b.AddBlock(BlockPlan{Name: "Delay", ModelName: "Simple Delay", Path: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AssignController(delay, "Feedback", Controller{ID: ControllerExp1, Min: 0, Max: 1})
+		b.SetSnapshotValue(delay, 3, "Feedback", 0.95)
+		if max := nestedMap(tone, "controller", "dsp1", delay.Key, "Feedback")["@max"]; max != 0.75 {
+			t.Errorf("controller @max = %v, want 0.75", max)
+		}
+		if v := nestedMap(tone, "snapshot3", "controllers", "dsp1", delay.Key, "Feedback")["@value"]; v != 0.75 {
+			t.Errorf("snapshot value = %v, want 0.75", v)
+		}
+	})
+
+	t.Run("Outputs", func(t *testing.T) {
+		b.SetOutput(0, OutputPath2)
+		if v := nestedMap(tone, "dsp0", "outputA")["@output"]; v != OutputPath2 {
+			t.Errorf("dsp0 output = %v, want %d", v, OutputPath2)
+		}
+	})
+}
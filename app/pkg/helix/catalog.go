@@ -21,10 +21,20 @@ type CatalogEntry struct {
 	DSPMono      float64                `json:"DSP_Mono,omitempty"`
 	DSPStereo    float64                `json:"DSP_Stereo,omitempty"`
 	Data         map[string]interface{} `json:"Data"` // Full block data
+
+	// Metadata derived at load time from effects_correspondance.json and the internal ID
+	Category    string `json:"Category,omitempty"`    // amp, cab, distortion, delay, reverb, wah, ...
+	Subcategory string `json:"Subcategory,omitempty"` // e.g. compressor, gate, chorus, whammy
+	Mono        bool   `json:"Mono,omitempty"`        // Available as a mono block
+	Stereo      bool   `json:"Stereo,omitempty"`      // Available as a stereo block
+	Legacy      bool   `json:"Legacy,omitempty"`      // Legacy stompbox/modeler ports (DL4, DM4, MM4, FM4, M13)
 }
 
 type CatalogDB struct {
-	Entries []CatalogEntry
+	Entries  []CatalogEntry
+	Firmware string          // Firmware version of the catalog (empty = LatestFirmware)
+	exclude  map[string]bool // Models of the embedded catalog missing from this firmware
+	mu       sync.RWMutex    // Guards Entries and the indexes against Merge
 	// Indexes for fast lookup
 	byInternalName map[string]CatalogEntry
 	byName         map[string]CatalogEntry // Display Name -> Entry
@@ -33,12 +43,29 @@ type CatalogDB struct {
 
 func (db *CatalogDB) EnsureLoaded() {
 	db.once.Do(func() {
+		if db.Firmware == "" {
+			db.Firmware = LatestFirmware
+		}
 		if err := json.Unmarshal(catalogJSON, &db.Entries); err != nil {
 			panic("Failed to load embedded catalog.json: " + err.Error())
 		}
+		if len(db.exclude) > 0 {
+			kept := db.Entries[:0]
+			for _, e := range db.Entries {
+				if !db.exclude[e.InternalName] {
+					kept = append(kept, e)
+				}
+			}
+			db.Entries = kept
+		}
 		db.byInternalName = make(map[string]CatalogEntry)
 		db.byName = make(map[string]CatalogEntry)
 
+		correspondance := loadCorrespondance()
+		for i := range db.Entries {
+			classify(&db.Entries[i], correspondance)
+		}
+
 		for _, e := range db.Entries {
 			db.byInternalName[e.InternalName] = e
 			if e.Name != "" {
@@ -51,6 +78,8 @@ func (db *CatalogDB) EnsureLoaded() {
 // FindByRealName attempts to find a model by its display name (case insensitive)
 func (db *CatalogDB) FindByRealName(name string) (CatalogEntry, bool) {
 	db.EnsureLoaded()
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 	entry, ok := db.byName[strings.ToLower(name)]
 	return entry, ok
 }
@@ -58,15 +87,24 @@ func (db *CatalogDB) FindByRealName(name string) (CatalogEntry, bool) {
 // FindByID finds by Internal Name
 func (db *CatalogDB) FindByID(id string) (CatalogEntry, bool) {
 	db.EnsureLoaded()
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 	entry, ok := db.byInternalName[id]
 	return entry, ok
 }
 
+// All returns the entries of the catalog. Merge never modifies a returned slice; callers must not either.
+func (db *CatalogDB) All() []CatalogEntry {
+	db.EnsureLoaded()
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.Entries
+}
+
 // GetAllModels returns a list of "Real Name (Based On)" for the AI prompt
 func (db *CatalogDB) GetAllModels() []string {
-	db.EnsureLoaded()
 	var list []string
-	for _, e := range db.Entries {
+	for _, e := range db.All() {
 		if e.Name != "" {
 			list = append(list, e.Name)
 		} else {
@@ -78,7 +116,6 @@ func (db *CatalogDB) GetAllModels() []string {
 
 // IsValidModel checks if id exists
 func IsValidModel(id string) bool {
-	DB.EnsureLoaded()
-	_, ok := DB.byInternalName[id]
+	_, ok := DB.FindByID(id)
 	return ok
 }
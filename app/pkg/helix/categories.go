@@ -0,0 +1,192 @@
+package helix
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+//go:embed data/effects_correspondance.json
+var correspondanceJSON []byte
+
+// Block categories
+const (
+	CategoryAmp        = "amp"
+	CategoryCab        = "cab"
+	CategoryDistortion = "distortion"
+	CategoryDynamics   = "dynamics"
+	CategoryEQ         = "eq"
+	CategoryModulation = "modulation"
+	CategoryDelay      = "delay"
+	CategoryReverb     = "reverb"
+	CategoryPitchSynth = "pitch_synth"
+	CategoryFilter     = "filter"
+	CategoryWah        = "wah"
+	CategoryVolume     = "volume"
+	CategoryRouting    = "routing"
+	CategoryOther      = "other"
+)
+
+// Legacy model families (ported from the M-Series / DL4 / DM4 / MM4 / FM4 stompboxes)
+var legacyPrefixes = []string{"HD2_DL4", "HD2_DM4", "HD2_MM4", "HD2_FM4", "HD2_M13"}
+
+// correspondanceSections maps the cheat-sheet sections to catalog categories.
+// "reverb_wah_volume" is resolved per model from the internal ID.
+var correspondanceSections = map[string]string{
+	"guitar_amps":        CategoryAmp,
+	"bass_amps":          CategoryAmp,
+	"cabinets":           CategoryCab,
+	"distortion":         CategoryDistortion,
+	"modulation":         CategoryModulation,
+	"dynamics_eq":        CategoryDynamics,
+	"delay":              CategoryDelay,
+	"pitch_synth_filter": CategoryPitchSynth,
+}
+
+// loadCorrespondance returns display name (lowercase) -> category from effects_correspondance.json
+func loadCorrespondance() map[string]string {
+	var root map[string]map[string][]struct {
+		Model   string `json:"model"`
+		BasedOn string `json:"based_on"`
+	}
+	byName := make(map[string]string)
+	if err := json.Unmarshal(correspondanceJSON, &root); err != nil {
+		return byName
+	}
+	for _, sections := range root {
+		for section, models := range sections {
+			category, ok := correspondanceSections[section]
+			if !ok {
+				continue
+			}
+			for _, m := range models {
+				byName[strings.ToLower(m.Model)] = category
+			}
+		}
+	}
+	return byName
+}
+
+// classify fills the metadata fields of a catalog entry.
+// The cheat-sheet section wins when the display name is listed there, the internal ID is used otherwise.
+func classify(e *CatalogEntry, correspondance map[string]string) {
+	category := inferCategory(e.InternalName)
+	if c, ok := correspondance[strings.ToLower(e.Name)]; ok {
+		// The cheat-sheet groups EQ with dynamics and filters with pitch/synth; keep the finer ID-based split.
+		if !(c == CategoryDynamics && category == CategoryEQ) && !(c == CategoryPitchSynth && category == CategoryFilter) {
+			category = c
+		}
+	}
+	e.Category = category
+	e.Subcategory = inferSubcategory(e.InternalName, category)
+
+	e.Stereo = e.DSPStereo > 0
+	e.Mono = e.DSPMono > 0 || !e.Stereo
+
+	for _, p := range legacyPrefixes {
+		if strings.HasPrefix(e.InternalName, p) {
+			e.Legacy = true
+			break
+		}
+	}
+}
+
+// BlockType returns the value of the "@type" field expected by HX Edit for this block
+func (e CatalogEntry) BlockType() int {
+	switch e.Category {
+	case CategoryAmp:
+		if e.Subcategory == "preamp" {
+			return 2
+		}
+		return 1
+	case CategoryCab:
+		return 2
+	case CategoryDelay, CategoryReverb:
+		return 7
+	default:
+		return 0
+	}
+}
+
+// IsExpressionTarget reports whether the block is driven by an expression pedal by default (wah, volume, whammy)
+func (e CatalogEntry) IsExpressionTarget() bool {
+	return e.Category == CategoryWah || e.Category == CategoryVolume || e.Subcategory == "whammy"
+}
+
+// CategoryOf returns the category of a model ID, falling back to the ID naming scheme for unknown models
+func CategoryOf(internalID string) string {
+	if e, ok := DB.FindByID(internalID); ok {
+		return e.Category
+	}
+	return inferCategory(internalID)
+}
+
+// inferCategory derives a category from the internal model ID
+func inferCategory(internalID string) string {
+	id := strings.TrimPrefix(strings.TrimPrefix(internalID, "HD2_"), "VIC_")
+	switch {
+	case strings.HasPrefix(id, "Amp"), strings.HasPrefix(id, "Preamp"):
+		return CategoryAmp
+	case strings.HasPrefix(id, "Cab"):
+		return CategoryCab
+	case strings.HasPrefix(id, "Dist"):
+		return CategoryDistortion
+	case strings.HasPrefix(id, "Delay"), strings.HasPrefix(id, "DL4"), strings.HasPrefix(internalID, "Victoria_"):
+		return CategoryDelay
+	case strings.HasPrefix(id, "Reverb"):
+		return CategoryReverb
+	case strings.HasPrefix(id, "Wah"):
+		return CategoryWah
+	case strings.HasPrefix(id, "Vol"):
+		return CategoryVolume
+	case strings.HasPrefix(id, "EQ"), strings.HasPrefix(id, "Cali"):
+		return CategoryEQ
+	case strings.HasPrefix(id, "Compressor"), strings.HasPrefix(id, "DM4"), strings.HasPrefix(id, "Gate"):
+		return CategoryDynamics
+	case strings.HasPrefix(id, "Filter"), strings.HasPrefix(id, "FM4"):
+		return CategoryFilter
+	case strings.HasPrefix(id, "Pitch"), strings.HasPrefix(id, "Synth"), strings.HasPrefix(internalID, "L6SPB_"):
+		return CategoryPitchSynth
+	case strings.HasPrefix(id, "App"):
+		return CategoryRouting
+	case strings.HasPrefix(id, "Chorus"), strings.HasPrefix(id, "Flanger"), strings.HasPrefix(id, "Phaser"),
+		strings.HasPrefix(id, "Tremolo"), strings.HasPrefix(id, "Rotary"), strings.HasPrefix(id, "Ring"),
+		strings.HasPrefix(id, "MM4"), strings.HasPrefix(id, "M13"):
+		return CategoryModulation
+	default:
+		return CategoryOther
+	}
+}
+
+// inferSubcategory refines a category using keywords of the internal model ID
+func inferSubcategory(internalID, category string) string {
+	id := strings.ToLower(internalID)
+	keywords := map[string][]string{
+		CategoryAmp:        {"preamp"},
+		CategoryDynamics:   {"gate", "comp"},
+		CategoryModulation: {"chorus", "flanger", "phaser", "phase", "tremolo", "trem", "rotary", "rotor", "ring", "vibe", "vibrato", "panner", "dimension"},
+		CategoryPitchSynth: {"wham", "pitch", "harmony", "octav", "synth", "string"},
+		CategoryDelay:      {"reverse", "pingpong", "tape", "analog", "swell"},
+		CategoryReverb:     {"spring", "plate", "hall", "room", "shimmer"},
+	}
+	for _, k := range keywords[category] {
+		if strings.Contains(id, k) {
+			switch k {
+			case "wham":
+				return "whammy"
+			case "comp":
+				return "compressor"
+			case "phase":
+				return "phaser"
+			case "trem":
+				return "tremolo"
+			case "rotor":
+				return "rotary"
+			case "octav":
+				return "octave"
+			}
+			return k
+		}
+	}
+	return ""
+}
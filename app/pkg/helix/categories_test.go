@@ -0,0 +1,57 @@
+package helix
+
+import (
+	"testing"
+)
+
+func TestCatalogMetadata(t *testing.T) {
+	tests := []struct {
+		id          string
+		category    string
+		subcategory string
+		blockType   int
+		expression  bool
+		stereo      bool
+		legacy      bool
+	}{
+		{"HD2_AmpBritPlexiJump", CategoryAmp, "", 1, false, false, false},
+		{"HD2_CabMicIr_4x12BritV30", CategoryCab, "", 2, false, true, false},
+		{"HD2_DistScream808", CategoryDistortion, "", 0, false, true, false},
+		{"HD2_DL4TapeEchoStereo", CategoryDelay, "tape", 7, false, true, true},
+		{"VIC_ReverbShimmer", CategoryReverb, "shimmer", 7, false, true, false},
+		{"HD2_WahFassel", CategoryWah, "", 0, true, true, false},
+		{"HD2_PitchPitchWham", CategoryPitchSynth, "whammy", 0, true, true, false},
+		{"HD2_GateNoiseGate", CategoryDynamics, "gate", 0, false, true, false},
+		{"HD2_FM4ObiWah", CategoryFilter, "", 0, false, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			e, ok := DB.FindByID(tt.id)
+			if !ok {
+				t.Fatalf("%s not found in catalog", tt.id)
+			}
+			if e.Category != tt.category || e.Subcategory != tt.subcategory {
+				t.Errorf("category = %s/%s, want %s/%s", e.Category, e.Subcategory, tt.category, tt.subcategory)
+			}
+			if got := e.BlockType(); got != tt.blockType {
+				t.Errorf("BlockType() = %d, want %d", got, tt.blockType)
+			}
+			if got := e.IsExpressionTarget(); got != tt.expression {
+				t.Errorf("IsExpressionTarget() = %v, want %v", got, tt.expression)
+			}
+			if e.Stereo != tt.stereo || !e.Mono {
+				t.Errorf("mono/stereo = %v/%v, want true/%v", e.Mono, e.Stereo, tt.stereo)
+			}
+			if e.Legacy != tt.legacy {
+				t.Errorf("Legacy = %v, want %v", e.Legacy, tt.legacy)
+			}
+		})
+	}
+
+	t.Run("Unknown ID Fallback", func(t *testing.T) {
+		if got := CategoryOf("HD2_DelayNotInCatalog"); got != CategoryDelay {
+			t.Errorf("CategoryOf() = %s, want %s", got, CategoryDelay)
+		}
+	})
+}
@@ -0,0 +1,442 @@
+package helix
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultDSPCost is the estimated DSP (% of one chip) of models without a known cost
+const DefaultDSPCost = 3.0
+
+// BlockRef addresses a block of a preset
+type BlockRef struct {
+	Path int    `json:"path"` // DSP index
+	Key  string `json:"key"`  // e.g. "block3"
+}
+
+// EditResult is the preset after a manual edit, with the result of the consistency checks
+type EditResult struct {
+	Preset      *Preset      `json:"preset"`
+	DSP         []float64    `json:"dsp"`               // Estimated DSP usage of each path, in % of its chip
+	Diagnostics []Diagnostic `json:"diagnostics"`       // Validate after the edit
+	Dropped     []string     `json:"dropped,omitempty"` // Parameters the new model doesn't have (SwapModel)
+}
+
+// IsDualDSP reports whether the hardware has two DSP paths
+func IsDualDSP(hardware string) bool {
+	return strings.Contains(hardware, "Floor") || strings.Contains(hardware, "LT") || strings.Contains(hardware, "Rack")
+}
+
+// DSPCost returns the mono DSP cost of a model
+func (db *CatalogDB) DSPCost(modelID string) float64 {
+	if e, ok := db.FindByID(modelID); ok && e.DSPMono > 0 {
+		return e.DSPMono
+	}
+	return DefaultDSPCost
+}
+
+// Editor applies manual edits to an existing preset, without the agents.
+// Every edit keeps the block keys contiguous (block0..n in path order) and the snapshots consistent.
+type Editor struct {
+	Preset  *Preset
+	DB      *CatalogDB
+	DualDSP bool
+}
+
+// NewEditor returns an editor for a preset and the catalog of its firmware
+func NewEditor(p *Preset, db *CatalogDB, dualDSP bool) *Editor {
+	db.EnsureLoaded()
+	return &Editor{Preset: p, DB: db, DualDSP: dualDSP}
+}
+
+// Result repairs the snapshots, validates the edited preset and returns it
+func (e *Editor) Result(hardware string) *EditResult {
+	e.repairSnapshots()
+	return &EditResult{Preset: e.Preset, DSP: e.DSPUsage(), Diagnostics: e.DB.Validate(e.Preset, hardware)}
+}
+
+// DSPUsage returns the estimated DSP usage of each path
+func (e *Editor) DSPUsage() []float64 {
+	usage := make([]float64, 2)
+	for path := range usage {
+		for _, blk := range e.layout(path) {
+			model, _ := blk.params["@model"].(string)
+			usage[path] += e.DB.DSPCost(model)
+		}
+	}
+	return usage
+}
+
+// SetParam changes a parameter of a block. snapshot < 0 changes the base value; otherwise the parameter
+// is assigned to the snapshot controller (if it isn't yet) and only that snapshot's value changes.
+func (e *Editor) SetParam(ref BlockRef, param string, value interface{}, snapshot int) error {
+	params, entry, err := e.find(ref)
+	if err != nil {
+		return err
+	}
+	key := entry.ParamKey(param)
+	if _, ok := entry.Param(key); !ok {
+		return fmt.Errorf("model %s has no parameter %q", entry.Name, param)
+	}
+	value, ok := SanitizeParam(e.DB, entry.InternalName, key, value)
+	if !ok {
+		return fmt.Errorf("parameter %q of %s is locked by the safety rules", param, entry.Name)
+	}
+
+	if snapshot < 0 {
+		params[key] = value
+		return nil
+	}
+	if snapshot >= SnapshotCount {
+		return fmt.Errorf("invalid snapshot %d", snapshot)
+	}
+
+	b := e.builder()
+	block := PlacedBlock{Name: ref.Key, Entry: entry, Path: ref.Path, Key: ref.Key}
+	id, assigned := e.controller(ref, key)
+	if assigned && !isSnapshotController(id) {
+		// An expression pedal or MIDI CC assignment is not replaced behind the user's back
+		return fmt.Errorf("parameter %q of %s is assigned to controller %d, not to snapshots", param, entry.Name, id)
+	}
+	if !assigned {
+		// Seed every snapshot with the base value, so only the edited one changes
+		b.AssignController(block, key, SnapshotController(entry, key))
+		for s := 0; s < SnapshotCount; s++ {
+			b.SetSnapshotValue(block, s, key, params[key])
+		}
+	}
+	b.SetSnapshotValue(block, snapshot, key, value)
+	return nil
+}
+
+// SetSnapshotBypass turns a block on or off in a snapshot
+func (e *Editor) SetSnapshotBypass(ref BlockRef, snapshot int, enabled bool) error {
+	if _, _, err := e.find(ref); err != nil {
+		return err
+	}
+	if snapshot < 0 || snapshot >= SnapshotCount {
+		return fmt.Errorf("invalid snapshot %d", snapshot)
+	}
+	e.builder().SetSnapshotBypass(PlacedBlock{Path: ref.Path, Key: ref.Key}, snapshot, enabled)
+	return nil
+}
+
+// SwapModel replaces the model of a block, keeping the values (base, snapshots, controllers) of the
+// parameters both models share. It returns the parameters that were dropped.
+func (e *Editor) SwapModel(ref BlockRef, modelName string) ([]string, error) {
+	params, old, err := e.find(ref)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := e.DB.Resolve(modelName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownModel, modelName)
+	}
+	defaults, _ := entry.Data["Defaults"].(map[string]interface{})
+
+	swapped := make(map[string]interface{})
+	for k, v := range defaults {
+		swapped[k] = v
+	}
+	var dropped []string
+	for k, v := range params {
+		if strings.HasPrefix(k, "@") {
+			continue
+		}
+		if _, ok := entry.Param(k); ok {
+			if v, ok := SanitizeParam(e.DB, entry.InternalName, k, v); ok {
+				swapped[k] = v
+			}
+		} else if _, isDefault := old.Param(k); isDefault {
+			dropped = append(dropped, k)
+		}
+	}
+	for _, k := range []string{"@name", "@enabled", "@position", "@path"} {
+		if v, ok := params[k]; ok {
+			swapped[k] = v
+		}
+	}
+	swapped["@model"] = entry.InternalName
+	swapped["@type"] = entry.BlockType()
+	nestedMap(e.tone(), fmt.Sprintf("dsp%d", ref.Path))[ref.Key] = swapped
+
+	// Controllers of dropped parameters would point at nothing
+	for _, section := range e.blockSections() {
+		if ctrls := nestedMap(e.tone(), append(section, fmt.Sprintf("dsp%d", ref.Path), ref.Key)...); ctrls != nil {
+			for _, k := range dropped {
+				delete(ctrls, k)
+			}
+		}
+	}
+	e.sanitizeAssignments(ref, entry)
+	sort.Strings(dropped)
+	return dropped, nil
+}
+
+// sanitizeAssignments applies the safety rules of a block's model to its controller ranges and snapshot values.
+// An assignment of a parameter that must not be written is removed.
+func (e *Editor) sanitizeAssignments(ref BlockRef, entry CatalogEntry) {
+	dspKey := fmt.Sprintf("dsp%d", ref.Path)
+	b := e.builder()
+	if ctrls := nestedMap(e.tone(), "controller", dspKey, ref.Key); ctrls != nil {
+		for k, raw := range ctrls {
+			ctrl, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, bound := range []string{"@min", "@max"} {
+				v, isNumber := ctrl[bound].(float64)
+				if !isNumber {
+					continue
+				}
+				v, ok := b.sanitizeBound(entry, k, v)
+				if !ok {
+					delete(ctrls, k)
+					break
+				}
+				ctrl[bound] = v
+			}
+		}
+	}
+	for s := 0; s < SnapshotCount; s++ {
+		values := nestedMap(e.tone(), fmt.Sprintf("snapshot%d", s), "controllers", dspKey, ref.Key)
+		for k, raw := range values {
+			value, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if v, ok := SanitizeParam(e.DB, entry.InternalName, k, value["@value"]); ok {
+				value["@value"] = v
+			} else {
+				delete(values, k)
+			}
+		}
+	}
+}
+
+// MoveBlock moves a block to a position of a path (position < 0 = end of the path), shifting the
+// other blocks. Its snapshot states, controllers and footswitch assignments move with it.
+func (e *Editor) MoveBlock(ref BlockRef, path, position int) (BlockRef, error) {
+	if _, _, err := e.find(ref); err != nil {
+		return ref, err
+	}
+	if path != 0 && (path != 1 || !e.DualDSP) {
+		return ref, fmt.Errorf("path %d is not available on this hardware", path+1)
+	}
+
+	from := e.layout(ref.Path)
+	idx := indexOfBlock(from, ref.Key)
+	moved := from[idx]
+	from = append(from[:idx:idx], from[idx+1:]...)
+
+	to := from
+	if path != ref.Path {
+		to = e.layout(path)
+	}
+	if position < 0 || position > len(to) {
+		position = len(to)
+	}
+	to = append(to[:position:position], append([]detachedBlock{moved}, to[position:]...)...)
+
+	if path != ref.Path {
+		e.writeLayout(ref.Path, from)
+	}
+	e.writeLayout(path, to)
+	e.routeOutputs()
+	return BlockRef{Path: path, Key: fmt.Sprintf("block%d", position)}, nil
+}
+
+// DeleteBlock removes a block with its snapshot states and controllers; the following blocks move up
+func (e *Editor) DeleteBlock(ref BlockRef) error {
+	if _, _, err := e.find(ref); err != nil {
+		return err
+	}
+	blocks := e.layout(ref.Path)
+	idx := indexOfBlock(blocks, ref.Key)
+	e.writeLayout(ref.Path, append(blocks[:idx:idx], blocks[idx+1:]...))
+	e.routeOutputs()
+	return nil
+}
+
+// routeOutputs routes Path 1 into Path 2 while Path 2 has blocks, and to the Multi output otherwise, as the engineer does
+func (e *Editor) routeOutputs() {
+	if e.DualDSP && len(e.layout(1)) > 0 {
+		e.builder().SetOutput(0, OutputPath2)
+	} else {
+		e.builder().SetOutput(0, OutputMulti)
+	}
+}
+
+// find returns the parameters and the catalog entry of a block
+func (e *Editor) find(ref BlockRef) (map[string]interface{}, CatalogEntry, error) {
+	params := nestedMap(e.tone(), fmt.Sprintf("dsp%d", ref.Path), ref.Key)
+	if params == nil || !strings.HasPrefix(ref.Key, "block") {
+		return nil, CatalogEntry{}, fmt.Errorf("no block %s on path %d", ref.Key, ref.Path+1)
+	}
+	model, _ := params["@model"].(string)
+	entry, ok := e.DB.FindByID(model)
+	if !ok {
+		if entry, ok = DB.FindByID(model); !ok {
+			return nil, CatalogEntry{}, fmt.Errorf("%w: %s", ErrUnknownModel, model)
+		}
+	}
+	return params, entry, nil
+}
+
+func (e *Editor) tone() map[string]interface{} {
+	tone, _ := e.Preset.tone()
+	return tone
+}
+
+func (e *Editor) builder() *Builder {
+	return &Builder{Preset: e.Preset, DB: e.DB, DualDSP: e.DualDSP}
+}
+
+// controller returns the controller a block parameter is assigned to, and false if it has none
+func (e *Editor) controller(ref BlockRef, key string) (int, bool) {
+	ctrl := nestedMap(e.tone(), "controller", fmt.Sprintf("dsp%d", ref.Path), ref.Key, key)
+	return number(ctrl["@controller"])
+}
+
+// snapshotControlled reports whether a block parameter is assigned to the snapshot controller
+func (e *Editor) snapshotControlled(ref BlockRef, key string) bool {
+	id, ok := e.controller(ref, key)
+	return ok && isSnapshotController(id)
+}
+
+// blockSections returns the sections of the tone indexed by DSP then block key, besides the DSPs themselves
+func (e *Editor) blockSections() [][]string {
+	sections := [][]string{{"controller"}, {"footswitch"}}
+	for s := 0; s < SnapshotCount; s++ {
+		snap := fmt.Sprintf("snapshot%d", s)
+		sections = append(sections, []string{snap, "blocks"}, []string{snap, "controllers"})
+	}
+	return sections
+}
+
+// detachedBlock is a block with everything indexed by its key, while the blocks of a path are re-keyed
+type detachedBlock struct {
+	key    string
+	params map[string]interface{}
+	linked []interface{} // Entry of each blockSections() (nil = none)
+}
+
+// layout returns the blocks of a path in position order
+func (e *Editor) layout(path int) []detachedBlock {
+	dspKey := fmt.Sprintf("dsp%d", path)
+	dsp := nestedMap(e.tone(), dspKey)
+	sections := e.blockSections()
+
+	var blocks []detachedBlock
+	for key, raw := range dsp {
+		params, ok := raw.(map[string]interface{})
+		if !ok || !strings.HasPrefix(key, "block") {
+			continue
+		}
+		blk := detachedBlock{key: key, params: params, linked: make([]interface{}, len(sections))}
+		for i, section := range sections {
+			if m := nestedMap(e.tone(), append(section, dspKey)...); m != nil {
+				blk.linked[i] = m[key]
+			}
+		}
+		blocks = append(blocks, blk)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		pi, pj := position(blocks[i].params), position(blocks[j].params)
+		if pi != pj {
+			return pi < pj
+		}
+		return blocks[i].key < blocks[j].key
+	})
+	return blocks
+}
+
+// writeLayout replaces the blocks of a path, keyed and positioned by their order
+func (e *Editor) writeLayout(path int, blocks []detachedBlock) {
+	dspKey := fmt.Sprintf("dsp%d", path)
+	tone := e.tone()
+	sections := e.blockSections()
+
+	dsp := nestedMap(tone, dspKey)
+	if dsp == nil {
+		return
+	}
+	for key := range dsp {
+		if strings.HasPrefix(key, "block") {
+			delete(dsp, key)
+		}
+	}
+	for _, section := range sections {
+		if m := nestedMap(tone, append(section, dspKey)...); m != nil {
+			for key := range m {
+				if strings.HasPrefix(key, "block") {
+					delete(m, key)
+				}
+			}
+		}
+	}
+
+	for pos, blk := range blocks {
+		key := fmt.Sprintf("block%d", pos)
+		blk.params["@position"] = pos
+		dsp[key] = blk.params
+		for i, section := range sections {
+			if blk.linked[i] == nil {
+				continue
+			}
+			if parent := nestedMap(tone, section...); parent != nil {
+				if m := childMap(parent, dspKey); m != nil {
+					m[key] = blk.linked[i]
+				}
+			}
+		}
+	}
+}
+
+// repairSnapshots gives every block a state in every snapshot (the block's own state when missing)
+func (e *Editor) repairSnapshots() {
+	tone := e.tone()
+	for path := 0; path < 2; path++ {
+		blocks := e.layout(path)
+		if len(blocks) == 0 {
+			continue
+		}
+		for s := 0; s < SnapshotCount; s++ {
+			snap := nestedMap(tone, fmt.Sprintf("snapshot%d", s))
+			if snap == nil {
+				continue
+			}
+			states := childMap(snap, "blocks", fmt.Sprintf("dsp%d", path))
+			if states == nil {
+				continue
+			}
+			for _, blk := range blocks {
+				if _, ok := states[blk.key].(bool); !ok {
+					enabled, _ := blk.params["@enabled"].(bool)
+					states[blk.key] = enabled
+				}
+			}
+		}
+	}
+}
+
+func indexOfBlock(blocks []detachedBlock, key string) int {
+	for i, blk := range blocks {
+		if blk.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// position returns the "@position" of a block (int when built, float64 when decoded from JSON)
+func position(params map[string]interface{}) float64 {
+	switch v := params["@position"].(type) {
+	case int:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,254 @@
+package helix
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// newTestEditor returns an editor on a preset with Scream 808 > Brit Plexi Brt > Minotaur on Path 1,
+// the drive assigned to the snapshot controller
+func newTestEditor(t *testing.T, dualDSP bool) *Editor {
+	t.Helper()
+	b := newTestBuilder(t, dualDSP)
+	drive, _ := b.AddBlock(BlockPlan{Name: "Drive", ModelName: "Scream 808", Params: map[string]interface{}{"Gain": 0.4, "Tone": 0.6}})
+	b.AddBlock(BlockPlan{Name: "Amp", ModelName: "Brit Plexi Brt"})
+	b.AddBlock(BlockPlan{Name: "Boost", ModelName: "Minotaur"})
+	b.AssignController(drive, "Tone", Controller{ID: ControllerSnapshot, Min: 0, Max: 1})
+	b.SetSnapshotValue(drive, 1, "Tone", 0.9)
+	b.SetSnapshotBypass(drive, 1, false)
+	return NewEditor(b.Preset, b.DB, dualDSP)
+}
+
+func mustFind(t *testing.T, e *Editor, ref BlockRef) CatalogEntry {
+	t.Helper()
+	_, entry, err := e.find(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+func blockNames(e *Editor, path int) []string {
+	var names []string
+	for _, blk := range e.layout(path) {
+		names = append(names, blk.params["@name"].(string))
+	}
+	return names
+}
+
+func TestEditorSetParam(t *testing.T) {
+	e := newTestEditor(t, true)
+	ref := BlockRef{Path: 0, Key: "block1"}
+
+	if err := e.SetParam(ref, "Gain", 6.0, -1); err != nil {
+		t.Fatalf("SetParam() error: %v", err)
+	}
+	if got := nestedMap(e.tone(), "dsp0", "block1")["Drive"]; got != 0.6 {
+		t.Errorf("Drive = %v, want 0.6 (alias and knob scale)", got)
+	}
+
+	if err := e.SetParam(ref, "Bass", 0.8, 2); err != nil {
+		t.Fatalf("SetParam(snapshot) error: %v", err)
+	}
+	if !e.snapshotControlled(ref, "Bass") {
+		t.Errorf("Bass was not assigned to the snapshot controller")
+	}
+	base := nestedMap(e.tone(), "dsp0", "block1")["Bass"]
+	for s, want := range map[int]interface{}{0: base, 2: 0.8, 7: base} {
+		if got := nestedMap(e.tone(), fmt.Sprintf("snapshot%d", s), "controllers", "dsp0", "block1", "Bass")["@value"]; got != want {
+			t.Errorf("snapshot%d Bass = %v, want %v", s, got, want)
+		}
+	}
+
+	// An expression pedal assignment is not replaced by the snapshot controller
+	amp := PlacedBlock{Path: 0, Key: "block1", Entry: mustFind(t, e, ref)}
+	e.builder().AssignController(amp, "Treble", Controller{ID: ControllerExp1, Min: 0.2, Max: 0.8})
+	if err := e.SetParam(ref, "Treble", 0.5, 1); err == nil {
+		t.Errorf("SetParam(snapshot) on a pedal-controlled parameter should fail")
+	}
+	if id, _ := e.controller(ref, "Treble"); id != ControllerExp1 {
+		t.Errorf("Treble controller = %d, want the pedal kept", id)
+	}
+
+	if err := e.SetParam(ref, "Flux Capacitor", 1.0, -1); err == nil {
+		t.Errorf("SetParam() on an unknown parameter should fail")
+	}
+	if err := e.SetParam(BlockRef{Path: 0, Key: "block9"}, "Gain", 1.0, -1); err == nil {
+		t.Errorf("SetParam() on a missing block should fail")
+	}
+}
+
+// TestEditorSetParamSnapshotIDs edits presets whose snapshot controller was written by HX Edit (19) or by older HelAIx builds (9)
+func TestEditorSetParamSnapshotIDs(t *testing.T) {
+	for _, id := range []float64{ControllerSnapshot, controllerSnapshotLegacy} {
+		t.Run(fmt.Sprintf("Controller %v", id), func(t *testing.T) {
+			b := newTestBuilder(t, true)
+			delay, _ := b.AddBlock(BlockPlan{Name: "Delay", ModelName: "Simple Delay"})
+			dsp := fmt.Sprintf("dsp%d", delay.Path)
+			// Numbers decoded from an .hlx file are float64
+			childMap(b.section("controller"), dsp, delay.Key)["Time"] = map[string]interface{}{
+				"@controller": id, "@min": 0.0, "@max": 8.0, "@snapshot_disable": false,
+			}
+			for s := 0; s < SnapshotCount; s++ {
+				b.SetSnapshotValue(delay, s, "Time", 0.25*float64(s+1))
+			}
+			snapshotTime := func(e *Editor, s int) interface{} {
+				return nestedMap(e.tone(), fmt.Sprintf("snapshot%d", s), "controllers", dsp, delay.Key, "Time")["@value"]
+			}
+
+			e := NewEditor(b.Preset, b.DB, true)
+			before := make([]interface{}, SnapshotCount)
+			for s := range before {
+				before[s] = snapshotTime(e, s)
+			}
+			if err := e.SetParam(BlockRef{Path: delay.Path, Key: delay.Key}, "Time", 1.5, 3); err != nil {
+				t.Fatalf("SetParam() error: %v", err)
+			}
+			for s := 0; s < SnapshotCount; s++ {
+				got := snapshotTime(e, s)
+				if s == 3 && got == before[s] {
+					t.Errorf("snapshot3 Time was not edited")
+				}
+				if s != 3 && got != before[s] {
+					t.Errorf("snapshot%d Time = %v, want %v (other snapshots must keep their value)", s, got, before[s])
+				}
+			}
+		})
+	}
+
+	t.Run("Seeds Catalog Range", func(t *testing.T) {
+		b := newTestBuilder(t, true)
+		delay, _ := b.AddBlock(BlockPlan{Name: "Delay", ModelName: "Simple Delay"})
+		e := NewEditor(b.Preset, b.DB, true)
+		if err := e.SetParam(BlockRef{Path: delay.Path, Key: delay.Key}, "Time", 1.5, 2); err != nil {
+			t.Fatalf("SetParam() error: %v", err)
+		}
+		ctrl := nestedMap(e.tone(), "controller", fmt.Sprintf("dsp%d", delay.Path), delay.Key, "Time")
+		p, _ := delay.Entry.Param("Time")
+		if ctrl["@controller"] != ControllerSnapshot || ctrl["@min"] != p.Min || ctrl["@max"] != p.Max {
+			t.Errorf("controller = %v, want snapshot controller over %v..%v", ctrl, p.Min, p.Max)
+		}
+	})
+}
+
+func TestEditorSwapModel(t *testing.T) {
+	e := newTestEditor(t, true)
+	dropped, err := e.SwapModel(BlockRef{Path: 0, Key: "block0"}, "KWB")
+	if err != nil {
+		t.Fatalf("SwapModel() error: %v", err)
+	}
+	if !reflect.DeepEqual(dropped, []string{"Tone"}) {
+		t.Errorf("dropped = %v, want [Tone]", dropped)
+	}
+	block := nestedMap(e.tone(), "dsp0", "block0")
+	if block["@model"] != "HD2_DistKWB" || block["@name"] != "Drive" || block["Gain"] != 0.4 {
+		t.Errorf("swapped block = %v", block)
+	}
+	if ctrl := nestedMap(e.tone(), "controller", "dsp0", "block0", "Tone"); ctrl != nil {
+		t.Errorf("controller of a dropped param was kept: %v", ctrl)
+	}
+
+	t.Run("Sanitizes Assignments", func(t *testing.T) {
+		b := newTestBuilder(t, true)
+		verb, _ := b.AddBlock(BlockPlan{Name: "Verb", ModelName: "HD2_ReverbHall"})
+		// Values beyond the safety rules, as an imported .hlx may have
+		childMap(b.section("controller"), "dsp0", verb.Key)["Decay"] = map[string]interface{}{
+			"@controller": ControllerExp1, "@min": 0.2, "@max": 0.95, "@snapshot_disable": false,
+		}
+		childMap(b.section("snapshot2"), "controllers", "dsp0", verb.Key)["Decay"] = map[string]interface{}{"@value": 0.95}
+
+		e := NewEditor(b.Preset, b.DB, true)
+		if _, err := e.SwapModel(BlockRef{Path: 0, Key: verb.Key}, "HD2_ReverbPlate"); err != nil {
+			t.Fatalf("SwapModel() error: %v", err)
+		}
+		if ctrl := nestedMap(e.tone(), "controller", "dsp0", verb.Key, "Decay"); ctrl["@max"] != 0.7 || ctrl["@min"] != 0.2 {
+			t.Errorf("controller = %v, want its max capped to 0.7", ctrl)
+		}
+		if v := nestedMap(e.tone(), "snapshot2", "controllers", "dsp0", verb.Key, "Decay")["@value"]; v != 0.7 {
+			t.Errorf("snapshot2 Decay = %v, want 0.7", v)
+		}
+	})
+}
+
+func TestEditorMoveAndDelete(t *testing.T) {
+	t.Run("Reorder", func(t *testing.T) {
+		e := newTestEditor(t, true)
+		ref, err := e.MoveBlock(BlockRef{Path: 0, Key: "block0"}, 0, 2)
+		if err != nil {
+			t.Fatalf("MoveBlock() error: %v", err)
+		}
+		if ref.Key != "block2" || !reflect.DeepEqual(blockNames(e, 0), []string{"Amp", "Boost", "Drive"}) {
+			t.Errorf("got %v at %s", blockNames(e, 0), ref.Key)
+		}
+		// Snapshot states and controllers follow the block
+		if nestedMap(e.tone(), "snapshot1", "blocks", "dsp0")["block2"] != false {
+			t.Errorf("snapshot state did not move with the block")
+		}
+		if !e.snapshotControlled(ref, "Tone") {
+			t.Errorf("controller did not move with the block")
+		}
+	})
+
+	t.Run("Move To Path 2", func(t *testing.T) {
+		e := newTestEditor(t, true)
+		ref, err := e.MoveBlock(BlockRef{Path: 0, Key: "block1"}, 1, -1)
+		if err != nil {
+			t.Fatalf("MoveBlock() error: %v", err)
+		}
+		if ref != (BlockRef{Path: 1, Key: "block0"}) || !reflect.DeepEqual(blockNames(e, 0), []string{"Drive", "Boost"}) {
+			t.Errorf("got %v / %v at %v", blockNames(e, 0), blockNames(e, 1), ref)
+		}
+		result := e.Result("Helix Floor")
+		if result.DSP[1] != 32 {
+			t.Errorf("DSP = %v, want the amp on Path 2", result.DSP)
+		}
+		if HasErrors(result.Diagnostics) {
+			t.Errorf("Diagnostics = %+v", result.Diagnostics)
+		}
+		if _, ok := nestedMap(e.tone(), "snapshot3", "blocks", "dsp1")["block0"].(bool); !ok {
+			t.Errorf("moved block has no snapshot state")
+		}
+		if out := nestedMap(e.tone(), "dsp0", "outputA")["@output"]; out != OutputPath2 {
+			t.Errorf("dsp0 output = %v, want Path 2 once it has blocks", out)
+		}
+		if _, err := e.MoveBlock(ref, 0, -1); err != nil {
+			t.Fatalf("MoveBlock() back error: %v", err)
+		}
+		if out := nestedMap(e.tone(), "dsp0", "outputA")["@output"]; out != OutputMulti {
+			t.Errorf("dsp0 output = %v, want Multi once Path 2 is empty", out)
+		}
+	})
+
+	t.Run("Single DSP", func(t *testing.T) {
+		e := newTestEditor(t, false)
+		if _, err := e.MoveBlock(BlockRef{Path: 0, Key: "block1"}, 1, 0); err == nil {
+			t.Errorf("MoveBlock() to Path 2 should fail on single DSP hardware")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		e := newTestEditor(t, true)
+		if err := e.DeleteBlock(BlockRef{Path: 0, Key: "block0"}); err != nil {
+			t.Fatalf("DeleteBlock() error: %v", err)
+		}
+		if !reflect.DeepEqual(blockNames(e, 0), []string{"Amp", "Boost"}) {
+			t.Errorf("got %v", blockNames(e, 0))
+		}
+		if nestedMap(e.tone(), "controller", "dsp0", "block0") != nil {
+			t.Errorf("controllers of the deleted block were kept")
+		}
+		if _, ok := nestedMap(e.tone(), "snapshot0", "blocks", "dsp0")["block2"]; ok {
+			t.Errorf("snapshot state of a removed key was kept")
+		}
+
+		// Deleting the last block of Path 2 stops routing Path 1 into it
+		ref, _ := e.MoveBlock(BlockRef{Path: 0, Key: "block1"}, 1, -1)
+		if err := e.DeleteBlock(ref); err != nil {
+			t.Fatalf("DeleteBlock() error: %v", err)
+		}
+		if out := nestedMap(e.tone(), "dsp0", "outputA")["@output"]; out != OutputMulti {
+			t.Errorf("dsp0 output = %v, want Multi", out)
+		}
+	})
+}
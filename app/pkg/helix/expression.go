@@ -0,0 +1,76 @@
+package helix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ToeSwitchIndex is the "@fs_index" of the toe switch of the built-in expression pedal
+const ToeSwitchIndex = 13
+
+// ErrNoSuchPedal is returned when an assignment targets an expression pedal the hardware does not have
+var ErrNoSuchPedal = errors.New("no such expression pedal")
+
+// Expression assigns a block parameter to an expression pedal
+type Expression struct {
+	Param      string  `json:"param"`                 // Parameter name, e.g. "Mix" or "Pedal"
+	Pedal      int     `json:"pedal"`                 // 1, 2 or 3 (EXP 1, EXP 2, EXP 3)
+	Heel       float64 `json:"heel"`                  // Value with the pedal heel down
+	Toe        float64 `json:"toe"`                   // Value with the pedal toe down
+	AutoEngage bool    `json:"auto_engage,omitempty"` // Turn the block on and off with the toe switch (wahs)
+}
+
+// ExpressionPedals returns the number of expression pedals the hardware can use: the built-in pedal
+// (EXP 1/2 with its toe switch) plus an EXP 3 jack on the Floor and LT, two jacks elsewhere
+func ExpressionPedals(hardware string) int {
+	if strings.Contains(hardware, "Floor") || strings.Contains(hardware, "LT") || strings.Contains(hardware, "Rack") {
+		return 3
+	}
+	return 2
+}
+
+// HasToeSwitch reports whether the hardware has a built-in expression pedal with a toe switch
+func HasToeSwitch(hardware string) bool {
+	return strings.Contains(hardware, "Floor") || strings.Contains(hardware, "LT")
+}
+
+// AssignExpression assigns a block parameter to an expression pedal with its heel/toe range.
+// Heel and toe go through the safety rules of the parameter, and may be reversed (toe below heel).
+// AutoEngage is ignored without a toe switch.
+func (b *Builder) AssignExpression(block PlacedBlock, exp Expression, hardware string) error {
+	if exp.Pedal < ControllerExp1 || exp.Pedal > ExpressionPedals(hardware) {
+		return fmt.Errorf("EXP %d on %s: %w", exp.Pedal, hardware, ErrNoSuchPedal)
+	}
+	key := block.Entry.ParamKey(exp.Param)
+	info, ok := block.Entry.Param(key)
+	if !ok || info.Type == ParamBool {
+		return fmt.Errorf("%s has no continuous parameter %q", block.Entry.Name, exp.Param)
+	}
+	b.AssignController(block, key, Controller{ID: exp.Pedal, Min: exp.Heel, Max: exp.Toe})
+
+	if exp.AutoEngage && HasToeSwitch(hardware) {
+		if fs := childMap(b.section("footswitch"), fmt.Sprintf("dsp%d", block.Path)); fs != nil {
+			fs[block.Key] = map[string]interface{}{
+				"@fs_enabled":   false,
+				"@fs_index":     ToeSwitchIndex,
+				"@fs_label":     block.Name,
+				"@fs_ledcolor":  0,
+				"@fs_momentary": false,
+				"@fs_primary":   true,
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultExpression is the assignment of blocks driven by an expression pedal by default (wah, volume, whammy):
+// their "Pedal" parameter on the given pedal (or the last one of the hardware) over the full range.
+// Wahs engage with the toe switch.
+func DefaultExpression(entry CatalogEntry, pedal int, hardware string) (Expression, bool) {
+	if pedal <= 0 || !entry.IsExpressionTarget() {
+		return Expression{}, false
+	}
+	pedal = min(pedal, ExpressionPedals(hardware))
+	return Expression{Param: "Pedal", Pedal: pedal, Heel: 0, Toe: 1, AutoEngage: entry.Category == CategoryWah && HasToeSwitch(hardware)}, true
+}
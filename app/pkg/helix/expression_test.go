@@ -0,0 +1,85 @@
+package helix
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuilderAssignExpression(t *testing.T) {
+	tests := []struct {
+		name      string
+		hardware  string
+		model     string
+		exp       Expression
+		wantErr   error
+		wantParam string
+		wantMin   float64
+		wantMax   float64
+		wantToe   bool
+	}{
+		{"Delay Mix", "Helix Floor", "Simple Delay", Expression{Param: "Mix", Pedal: 2, Heel: 0.1, Toe: 0.5}, nil, "Mix", 0.1, 0.5, false},
+		{"Knob Scale Reversed", "Helix LT", "Scream 808", Expression{Param: "Gain", Pedal: 3, Heel: 8, Toe: 2}, nil, "Gain", 0.8, 0.2, false},
+		{"Safety Rules", "Helix Floor", "Simple Delay", Expression{Param: "Feedback", Pedal: 1, Heel: 0, Toe: 1}, nil, "Feedback", 0, 0.75, false},
+		{"Wah Auto Engage", "Helix Floor", "HD2_WahWeeper", Expression{Param: "Pedal", Pedal: 1, Heel: 0, Toe: 1, AutoEngage: true}, nil, "Pedal", 0, 1, true},
+		{"No Toe Switch", "HX Stomp", "HD2_WahWeeper", Expression{Param: "Pedal", Pedal: 1, Heel: 0, Toe: 1, AutoEngage: true}, nil, "Pedal", 0, 1, false},
+		{"Missing Pedal", "HX Stomp", "Simple Delay", Expression{Param: "Mix", Pedal: 3, Toe: 1}, ErrNoSuchPedal, "", 0, 0, false},
+		{"Unknown Param", "Helix Floor", "Simple Delay", Expression{Param: "Wobble", Pedal: 1, Toe: 1}, nil, "", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBuilder(t, IsDualDSP(tt.hardware))
+			block, err := b.AddBlock(BlockPlan{Name: tt.name, ModelName: tt.model})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = b.AssignExpression(block, tt.exp, tt.hardware)
+			if tt.wantErr != nil || tt.wantParam == "" {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Errorf("AssignExpression() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AssignExpression() error: %v", err)
+			}
+
+			tone, _ := b.Preset.tone()
+			ctrl := nestedMap(tone, "controller", "dsp0", block.Key, tt.wantParam)
+			if ctrl["@controller"] != tt.exp.Pedal || ctrl["@min"] != tt.wantMin || ctrl["@max"] != tt.wantMax {
+				t.Errorf("controller = %v", ctrl)
+			}
+			fs := nestedMap(tone, "footswitch", "dsp0", block.Key)
+			if (fs["@fs_index"] == ToeSwitchIndex) != tt.wantToe {
+				t.Errorf("footswitch = %v, want toe switch %v", fs, tt.wantToe)
+			}
+			if diags := Validate(b.Preset, tt.hardware); HasErrors(diags) {
+				t.Errorf("Validate() = %+v", diags)
+			}
+		})
+	}
+}
+
+func TestDefaultExpression(t *testing.T) {
+	tests := []struct {
+		model    string
+		pedal    int
+		hardware string
+		want     bool
+		wantAuto bool
+	}{
+		{"HD2_WahWeeper", 1, "Helix Floor", true, true},
+		{"HD2_WahWeeper", 1, "HX Stomp", true, false},
+		{"HD2_VolPanVol", 2, "Helix Floor", true, false},
+		{"HD2_VolPanVol", 3, "HX Stomp", true, false},
+		{"HD2_WahWeeper", 0, "Helix Floor", false, false},
+		{"HD2_DistScream808", 1, "Helix Floor", false, false},
+	}
+	for _, tt := range tests {
+		entry, _ := DB.FindByID(tt.model)
+		exp, ok := DefaultExpression(entry, tt.pedal, tt.hardware)
+		if ok != tt.want || exp.AutoEngage != tt.wantAuto || exp.Pedal > ExpressionPedals(tt.hardware) {
+			t.Errorf("DefaultExpression(%s, %d, %s) = %+v, %v", tt.model, tt.pedal, tt.hardware, exp, ok)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package helix
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LatestFirmware is the firmware version of the default catalog (DB)
+const LatestFirmware = "3.80"
+
+//go:embed data/firmware_releases.json
+var releasesJSON []byte
+
+// firmwareRelease lists the models a firmware release added. The catalog of an older firmware is the
+// embedded (latest) catalog without the models added by the releases that came after it.
+// A release is only listed with a Source for its models (release notes or a model list of that firmware):
+// the catalogs of unlisted, older firmware are refused rather than guessed.
+type firmwareRelease struct {
+	Firmware string   `json:"firmware"`
+	Source   string   `json:"source"`
+	Added    []string `json:"added"` // Taken from the release notes; empty for the oldest release listed
+}
+
+var (
+	releasesOnce sync.Once
+	releases     []firmwareRelease
+)
+
+// firmwareReleases returns the known releases, newest first
+func firmwareReleases() []firmwareRelease {
+	releasesOnce.Do(func() {
+		var root struct {
+			Releases []firmwareRelease `json:"releases"`
+		}
+		if err := json.Unmarshal(releasesJSON, &root); err != nil {
+			panic("Failed to load embedded firmware_releases.json: " + err.Error())
+		}
+		releases = root.Releases
+		sort.Slice(releases, func(i, j int) bool {
+			return CompareFirmware(releases[i].Firmware, releases[j].Firmware) > 0
+		})
+	})
+	return releases
+}
+
+// addedAfter returns the models added by the releases newer than the given firmware
+func addedAfter(version string) map[string]bool {
+	added := make(map[string]bool)
+	for _, r := range firmwareReleases() {
+		if CompareFirmware(r.Firmware, version) > 0 {
+			for _, id := range r.Added {
+				added[id] = true
+			}
+		}
+	}
+	return added
+}
+
+var (
+	catalogsMu sync.Mutex
+	catalogs   = map[string]*CatalogDB{LatestFirmware: &DB}
+	overlay    []CatalogEntry // User-learned entries, merged into every catalog
+)
+
+// SupportedFirmware returns the firmware versions that have an embedded catalog, oldest first
+func SupportedFirmware() []string {
+	var versions []string
+	for _, r := range firmwareReleases() {
+		versions = append(versions, r.Firmware)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return CompareFirmware(versions[i], versions[j]) < 0
+	})
+	return versions
+}
+
+// CatalogFor returns the catalog matching the given firmware version.
+// If there is no exact match, the newest catalog not newer than the requested firmware is used.
+// An empty version selects the latest catalog.
+func CatalogFor(version string) (*CatalogDB, error) {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return &DB, nil
+	}
+
+	best := ""
+	for _, v := range SupportedFirmware() {
+		if CompareFirmware(v, version) <= 0 {
+			best = v
+		}
+	}
+	if best == "" {
+		return nil, fmt.Errorf("firmware %s is not supported (oldest supported: %s)", version, SupportedFirmware()[0])
+	}
+
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	db, ok := catalogs[best]
+	if !ok {
+		db = &CatalogDB{Firmware: best, exclude: addedAfter(best)}
+		catalogs[best] = db
+		db.Merge(overlay)
+	}
+	db.EnsureLoaded()
+	return db, nil
+}
+
+// ApplyOverlay merges user-learned entries into every catalog, including the ones loaded later
+func ApplyOverlay(entries []CatalogEntry) {
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	overlay = append(overlay, entries...)
+	for _, db := range catalogs {
+		db.Merge(entries)
+	}
+}
+
+// CompareFirmware compares two dotted firmware versions ("3.80" vs "3.71"). It returns -1, 0 or 1.
+func CompareFirmware(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(strings.TrimSpace(a), "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(strings.TrimSpace(b), "v"), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Resolve finds a model by display name or internal ID
+func (db *CatalogDB) Resolve(nameOrID string) (CatalogEntry, bool) {
+	if e, ok := db.FindByRealName(nameOrID); ok {
+		return e, true
+	}
+	return db.FindByID(nameOrID)
+}
+
+// Substitute returns the closest model available in this catalog for an entry coming from another firmware.
+// Candidates must share the category; they are ranked by similarity of their BasedOn and Name.
+func (db *CatalogDB) Substitute(e CatalogEntry) (CatalogEntry, bool) {
+	if found, ok := db.FindByID(e.InternalName); ok {
+		return found, true
+	}
+
+	for _, text := range []string{e.BasedOn, e.Name} {
+		if text == "" || text == "Unknown" {
+			continue
+		}
+		if results := db.Search(SearchQuery{Text: text, Category: e.Category, Limit: 1}); len(results) > 0 {
+			return db.FindByID(results[0].InternalName)
+		}
+		// Loosen: match on the first word only (usually the brand or family)
+		if words := tokenize(text); len(words) > 1 {
+			if results := db.Search(SearchQuery{Text: words[0], Category: e.Category, Limit: 1}); len(results) > 0 {
+				return db.FindByID(results[0].InternalName)
+			}
+		}
+	}
+	return CatalogEntry{}, false
+}
+
+// CompatibilityIssue describes a preset block whose model is missing from the target firmware
+type CompatibilityIssue struct {
+	DSP         string `json:"dsp"`
+	Block       string `json:"block"`
+	Model       string `json:"model"`
+	Replacement string `json:"replacement,omitempty"` // Empty when no substitute exists
+}
+
+// CheckCompatibility lists the blocks of the preset whose "@model" does not exist in the catalog
+func (db *CatalogDB) CheckCompatibility(p *Preset) []CompatibilityIssue {
+	db.EnsureLoaded()
+	var issues []CompatibilityIssue
+	tone, ok := p.tone()
+	if !ok {
+		return issues
+	}
+	for _, dspKey := range []string{"dsp0", "dsp1"} {
+		dsp, ok := tone[dspKey].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for blockKey, raw := range dsp {
+			block, ok := raw.(map[string]interface{})
+			if !ok || !(strings.HasPrefix(blockKey, "block") || strings.HasPrefix(blockKey, "cab")) {
+				continue
+			}
+			model, _ := block["@model"].(string)
+			if model == "" {
+				continue
+			}
+			if _, ok := db.FindByID(model); ok {
+				continue
+			}
+			issue := CompatibilityIssue{DSP: dspKey, Block: blockKey, Model: model}
+			if original, ok := DB.FindByID(model); ok {
+				if sub, ok := db.Substitute(original); ok {
+					issue.Replacement = sub.InternalName
+				}
+			}
+			issues = append(issues, issue)
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].DSP != issues[j].DSP {
+			return issues[i].DSP < issues[j].DSP
+		}
+		return issues[i].Block < issues[j].Block
+	})
+	return issues
+}
+
+// tone returns the data.tone section of the preset
+func (p *Preset) tone() (map[string]interface{}, bool) {
+	data, ok := (*p)["data"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	tone, ok := data["tone"].(map[string]interface{})
+	return tone, ok
+}
@@ -0,0 +1,96 @@
+package helix
+
+import (
+	"testing"
+)
+
+// knownModels are models each supported release is documented to have (see the source of the release).
+// A release added to firmware_releases.json needs an entry here.
+var knownModels = map[string][]string{
+	"3.80": {"HD2_AmpVoltageQueen", "HD2_AmpCartographer", "HD2_DistToneSovereign", "HD2_AmpBritPlexiJump"},
+}
+
+func TestFirmwareCatalogs(t *testing.T) {
+	t.Run("Compare Versions", func(t *testing.T) {
+		if CompareFirmware("3.71", "3.80") != -1 || CompareFirmware("3.80.1", "3.80") != 1 {
+			t.Errorf("CompareFirmware ordering is wrong")
+		}
+	})
+
+	t.Run("Nearest Older Catalog", func(t *testing.T) {
+		db, err := CatalogFor("3.90")
+		if err != nil {
+			t.Fatalf("CatalogFor(3.90) error: %v", err)
+		}
+		if db.Firmware != LatestFirmware {
+			t.Errorf("CatalogFor(3.90) = %s, want %s", db.Firmware, LatestFirmware)
+		}
+	})
+
+	t.Run("Refuse Unsourced Firmware", func(t *testing.T) {
+		for _, v := range []string{"2.00", "3.71"} {
+			if _, err := CatalogFor(v); err == nil {
+				t.Errorf("CatalogFor(%s) should fail: no sourced catalog", v)
+			}
+		}
+	})
+
+	t.Run("Releases Are Sourced", func(t *testing.T) {
+		releases := firmwareReleases()
+		for i, r := range releases {
+			if r.Source == "" {
+				t.Errorf("release %s has no source", r.Firmware)
+			}
+			if i < len(releases)-1 && len(r.Added) == 0 {
+				t.Errorf("release %s lists no added models", r.Firmware)
+			}
+			for _, id := range r.Added {
+				if _, ok := DB.FindByID(id); !ok {
+					t.Errorf("release %s adds %s, missing from the catalog", r.Firmware, id)
+				}
+			}
+		}
+	})
+
+	t.Run("Known Models Per Release", func(t *testing.T) {
+		for _, v := range SupportedFirmware() {
+			known, ok := knownModels[v]
+			if !ok {
+				t.Errorf("release %s has no known models to check", v)
+				continue
+			}
+			db, err := CatalogFor(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range known {
+				if _, ok := db.FindByID(id); !ok {
+					t.Errorf("%s catalog lacks %s", v, id)
+				}
+			}
+		}
+	})
+
+	t.Run("Substitute Missing Model", func(t *testing.T) {
+		// A catalog without Voltage Queen, as an older firmware would have
+		older := &CatalogDB{Firmware: "3.70", exclude: map[string]bool{"HD2_AmpVoltageQueen": true}}
+		preset := &Preset{
+			"data": map[string]interface{}{
+				"tone": map[string]interface{}{
+					"dsp0": map[string]interface{}{
+						"block0": map[string]interface{}{"@model": "HD2_AmpVoltageQueen"},
+						"block1": map[string]interface{}{"@model": "HD2_DistScream808"},
+					},
+				},
+			},
+		}
+		issues := older.CheckCompatibility(preset)
+		if len(issues) != 1 || issues[0].Block != "block0" {
+			t.Fatalf("CheckCompatibility() = %+v, want 1 issue on block0", issues)
+		}
+		sub, ok := older.FindByID(issues[0].Replacement)
+		if !ok || sub.Category != CategoryAmp {
+			t.Errorf("Replacement %q should be an amp of the older catalog", issues[0].Replacement)
+		}
+	})
+}
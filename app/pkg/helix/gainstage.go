@@ -0,0 +1,228 @@
+package helix
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// GainStaging configures the level normalization pass
+type GainStaging struct {
+	TargetDB    float64 // Estimated level every snapshot should land on (0 = the level of a template preset)
+	ToleranceDB float64 // Snapshots within this distance of the target are left alone
+	MaxTrimDB   float64 // Largest correction applied to the output block
+}
+
+// DefaultGainStaging keeps snapshots within ±1.5 dB of each other and of other presets
+var DefaultGainStaging = GainStaging{TargetDB: 0, ToleranceDB: 1.5, MaxTrimDB: 12}
+
+// Output block gain range ("gain" of outputA, in dB)
+const (
+	outputGainMin = -120.0
+	outputGainMax = 20.0
+)
+
+// SnapshotLevel is the estimated loudness of a snapshot, before and after normalization
+type SnapshotLevel struct {
+	Snapshot    int     `json:"snapshot"`
+	Name        string  `json:"name"`
+	EstimatedDB float64 `json:"estimated_db"` // Before normalization, relative to a template preset
+	TrimDB      float64 `json:"trim_db"`      // Correction applied to the output block
+	OutputDB    float64 `json:"output_db"`    // Resulting output block gain
+}
+
+// LevelResult is a preset after gain staging
+type LevelResult struct {
+	Preset    *Preset         `json:"preset"`
+	Snapshots []SnapshotLevel `json:"snapshots"`
+}
+
+// EstimateLevels estimates the loudness of each snapshot in dB, relative to a template preset.
+// It is a heuristic: amp channel volume and master, drive levels, dB level params of the enabled blocks
+// and the output block gain. Snapshots without any enabled block are skipped.
+func (db *CatalogDB) EstimateLevels(p *Preset) []SnapshotLevel {
+	db.EnsureLoaded()
+	tone, ok := p.tone()
+	if !ok {
+		return nil
+	}
+	out := outputDSP(tone)
+
+	var levels []SnapshotLevel
+	for s := 0; s < SnapshotCount; s++ {
+		snap := nestedMap(tone, fmt.Sprintf("snapshot%d", s))
+		if snap == nil {
+			continue
+		}
+		level, active := 0.0, 0
+		for path := 0; path < 2; path++ {
+			dspKey := fmt.Sprintf("dsp%d", path)
+			for key, raw := range nestedMap(tone, dspKey) {
+				block, ok := raw.(map[string]interface{})
+				if !ok || !strings.HasPrefix(key, "block") {
+					continue
+				}
+				enabled, ok := nestedMap(snap, "blocks", dspKey)[key].(bool)
+				if !ok {
+					enabled, _ = block["@enabled"].(bool)
+				}
+				if !enabled {
+					continue
+				}
+				active++
+				level += db.blockLevel(block, func(param string) (float64, bool) {
+					return snapshotValue(tone, snap, dspKey, key, param, block)
+				})
+			}
+		}
+		if active == 0 {
+			continue
+		}
+
+		gain, _ := snapshotValue(tone, snap, out, "outputA", "gain", nestedMap(tone, out, "outputA"))
+		name, _ := snap["@name"].(string)
+		levels = append(levels, SnapshotLevel{Snapshot: s, Name: name, EstimatedDB: round1(level + gain), OutputDB: gain})
+	}
+	return levels
+}
+
+// NormalizeLevels trims the output block so that every snapshot lands on the target level.
+// When the snapshots need different corrections, the output gain is assigned to the snapshot controller.
+func (db *CatalogDB) NormalizeLevels(p *Preset, opts GainStaging) *LevelResult {
+	result := &LevelResult{Preset: p, Snapshots: db.EstimateLevels(p)}
+	if len(result.Snapshots) == 0 {
+		return result
+	}
+	tone, _ := p.tone()
+	out := outputDSP(tone)
+	output := nestedMap(tone, out, "outputA")
+	if output == nil {
+		return result
+	}
+
+	same := true
+	for i := range result.Snapshots {
+		lvl := &result.Snapshots[i]
+		diff := opts.TargetDB - lvl.EstimatedDB
+		if math.Abs(diff) > opts.ToleranceDB {
+			lvl.TrimDB = round1(math.Max(-opts.MaxTrimDB, math.Min(opts.MaxTrimDB, diff)))
+		}
+		lvl.OutputDB = round1(math.Max(outputGainMin, math.Min(outputGainMax, lvl.OutputDB+lvl.TrimDB)))
+		same = same && lvl.OutputDB == result.Snapshots[0].OutputDB
+	}
+
+	ctrls := nestedMap(tone, "controller")
+	if same {
+		output["gain"] = result.Snapshots[0].OutputDB
+		if c := nestedMap(ctrls, out, "outputA"); c != nil {
+			delete(c, "gain")
+		}
+		for s := 0; s < SnapshotCount; s++ {
+			delete(nestedMap(tone, fmt.Sprintf("snapshot%d", s), "controllers", out, "outputA"), "gain")
+		}
+		return result
+	}
+
+	// Different trims per snapshot: the first one is the base value, the others are recalled by snapshot
+	output["gain"] = result.Snapshots[0].OutputDB
+	if c := childMap(ctrls, out, "outputA"); c != nil {
+		c["gain"] = map[string]interface{}{
+			"@controller":       ControllerSnapshot,
+			"@min":              outputGainMin,
+			"@max":              outputGainMax,
+			"@snapshot_disable": false,
+		}
+	}
+	byIndex := make(map[int]float64)
+	for _, lvl := range result.Snapshots {
+		byIndex[lvl.Snapshot] = lvl.OutputDB
+	}
+	for s := 0; s < SnapshotCount; s++ {
+		snap := nestedMap(tone, fmt.Sprintf("snapshot%d", s))
+		if snap == nil {
+			continue
+		}
+		gain, ok := byIndex[s]
+		if !ok {
+			gain = result.Snapshots[0].OutputDB
+		}
+		if c := childMap(snap, "controllers", out, "outputA"); c != nil {
+			c["gain"] = map[string]interface{}{"@value": gain}
+		}
+	}
+	return result
+}
+
+// blockLevel estimates the gain of one enabled block in dB
+func (db *CatalogDB) blockLevel(block map[string]interface{}, value func(string) (float64, bool)) float64 {
+	model, _ := block["@model"].(string)
+	entry, ok := db.FindByID(model)
+	if !ok {
+		return 0
+	}
+
+	level := 0.0
+	for _, info := range entry.Params() {
+		if info.Unit == UnitDB && strings.Contains(info.Name, "Level") {
+			if v, ok := value(info.Name); ok {
+				level += v
+			}
+		}
+	}
+
+	switch entry.Category {
+	case CategoryAmp:
+		// Channel volume is a post-amp gain, master also drives the power amp
+		if v, ok := value("ChVol"); ok {
+			level += knobDB(v)
+		}
+		if v, ok := value("Master"); ok {
+			level += knobDB(v) / 2
+		}
+		// More drive compresses and sounds louder
+		if v, ok := value("Drive"); ok {
+			level += 4 * (v - 0.5)
+		}
+	case CategoryDistortion:
+		if info, ok := entry.Param("Level"); ok && info.Unit != UnitDB {
+			if v, ok := value("Level"); ok {
+				level += knobDB(v)
+			}
+		}
+	}
+	return level
+}
+
+// knobDB converts a 0.0-1.0 volume knob to dB, noon (0.5) being unity
+func knobDB(v float64) float64 {
+	return 20 * math.Log10(math.Max(v, 0.05)/0.5)
+}
+
+// snapshotValue returns a numeric parameter, as recalled by the snapshot when it is snapshot-controlled
+func snapshotValue(tone, snap map[string]interface{}, dspKey, key, param string, block map[string]interface{}) (float64, bool) {
+	ctrl := nestedMap(tone, "controller", dspKey, key, param)
+	if id, _ := number(ctrl["@controller"]); isSnapshotController(id) {
+		if v, ok := nestedMap(snap, "controllers", dspKey, key, param)["@value"].(float64); ok {
+			return v, true
+		}
+	}
+	switch v := block[param].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// outputDSP returns the DSP whose output reaches the hardware: dsp1 when dsp0 feeds Path 2
+func outputDSP(tone map[string]interface{}) string {
+	if out, _ := number(nestedMap(tone, "dsp0", "outputA")["@output"]); out == OutputPath2 {
+		return "dsp1"
+	}
+	return "dsp0"
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,90 @@
+package helix
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeLevels(t *testing.T) {
+	tests := []struct {
+		name         string
+		chVol        []float64 // Amp channel volume per snapshot, a single value is the base value
+		wantSnapshot bool      // Output gain assigned to the snapshot controller
+	}{
+		{"Already Balanced", []float64{0.5}, false},
+		{"Loud Amp", []float64{1.0}, false},
+		{"Quiet Snapshot", []float64{0.5, 0.2, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBuilder(t, true)
+			amp, err := b.AddBlock(BlockPlan{Name: "Amp", ModelName: "Brit Plexi Brt", Params: map[string]interface{}{
+				"Drive": 0.5, "Master": 0.5, "ChVol": tt.chVol[0],
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for s := 0; s < SnapshotCount; s++ {
+				b.SetSnapshotBypass(amp, s, true)
+			}
+			if len(tt.chVol) > 1 {
+				b.AssignController(amp, "ChVol", Controller{ID: ControllerSnapshot, Min: 0, Max: 1})
+				for s, v := range tt.chVol {
+					b.SetSnapshotValue(amp, s, "ChVol", v)
+				}
+			}
+
+			result := DB.NormalizeLevels(b.Preset, DefaultGainStaging)
+			if len(result.Snapshots) != SnapshotCount {
+				t.Fatalf("got %d snapshot levels, want %d", len(result.Snapshots), SnapshotCount)
+			}
+			for _, lvl := range DB.EstimateLevels(b.Preset) {
+				if math.Abs(lvl.EstimatedDB-DefaultGainStaging.TargetDB) > DefaultGainStaging.ToleranceDB {
+					t.Errorf("snapshot %d estimated at %.1f dB after normalization", lvl.Snapshot, lvl.EstimatedDB)
+				}
+			}
+
+			tone, _ := b.Preset.tone()
+			ctrl := nestedMap(tone, "controller", "dsp0", "outputA", "gain")
+			if (ctrl != nil) != tt.wantSnapshot {
+				t.Errorf("output gain controller = %v, want snapshot controlled %v", ctrl, tt.wantSnapshot)
+			}
+			if diags := Validate(b.Preset, "Helix Floor"); HasErrors(diags) {
+				t.Errorf("Validate() = %+v", diags)
+			}
+		})
+	}
+
+	t.Run("Bypassed Snapshots Are Skipped", func(t *testing.T) {
+		b := newTestBuilder(t, true)
+		amp, err := b.AddBlock(BlockPlan{Name: "Amp", ModelName: "Brit Plexi Brt"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.SetSnapshotBypass(amp, 0, true)
+		for s := 1; s < SnapshotCount; s++ {
+			b.SetSnapshotBypass(amp, s, false)
+		}
+		if levels := DB.EstimateLevels(b.Preset); len(levels) != 1 || levels[0].Snapshot != 0 {
+			t.Errorf("EstimateLevels() = %+v, want snapshot 0 only", levels)
+		}
+	})
+}
+
+func TestKnobDB(t *testing.T) {
+	tests := []struct {
+		knob float64
+		want float64
+	}{
+		{0.5, 0},
+		{1.0, 6},
+		{0.25, -6},
+		{0, -20},
+	}
+	for _, tt := range tests {
+		if got := round1(knobDB(tt.knob)); math.Abs(got-tt.want) > 0.1 {
+			t.Errorf("knobDB(%v) = %v, want %v", tt.knob, got, tt.want)
+		}
+	}
+}
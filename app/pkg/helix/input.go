@@ -0,0 +1,119 @@
+package helix
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Input noise gate ranges
+const (
+	GateThresholdMin = -96.0 // dB
+	GateThresholdMax = 0.0
+	GateDecayMin     = 0.001 // Seconds
+	GateDecayMax     = 1.0
+)
+
+// Default input noise gates: the template one barely acts, high-gain presets need a tighter threshold
+var (
+	DefaultInput  = InputConfig{NoiseGate: boolPtr(true), Threshold: -75, Decay: 0.15}
+	HighGainInput = InputConfig{NoiseGate: boolPtr(true), Threshold: -56, Decay: 0.1}
+)
+
+// InputImpedances lists the Guitar In-Z settings in "@guitarinputZ" order
+var InputImpedances = []string{"Auto", "1M", "3.5M", "230k", "136k", "90k", "70k", "32k", "22k", "10k"}
+
+// InputConfig is the setting of the guitar input block (inputA of Path 1)
+type InputConfig struct {
+	NoiseGate *bool   `json:"noise_gate,omitempty"` // nil = default
+	Threshold float64 `json:"threshold,omitempty"`  // dB, 0 = default
+	Decay     float64 `json:"decay,omitempty"`      // Seconds, 0 = default
+	Impedance string  `json:"impedance,omitempty"`  // Guitar In-Z, e.g. "1M" or "230k" ("" = keep Auto)
+}
+
+// Over fills the settings left to default (nil gate, zero threshold or decay) from base
+func (c InputConfig) Over(base InputConfig) InputConfig {
+	if c.NoiseGate == nil {
+		c.NoiseGate = base.NoiseGate
+	}
+	if c.Threshold == 0 {
+		c.Threshold = base.Threshold
+	}
+	if c.Decay == 0 {
+		c.Decay = base.Decay
+	}
+	return c
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+// HasGuitarInputZ reports whether the hardware has a Guitar In-Z setting (HX Effects has no guitar input)
+func HasGuitarInputZ(hardware string) bool {
+	return !strings.Contains(hardware, "Effects")
+}
+
+// ImpedanceIndex returns the "@guitarinputZ" value of an impedance ("1M", "1 MOhm", "230kΩ"), or -1 if unknown
+func ImpedanceIndex(z string) int {
+	n := strings.ToUpper(strings.TrimSpace(z))
+	for _, suffix := range []string{"OHMS", "OHM", "Ω"} {
+		n = strings.TrimSuffix(n, suffix)
+	}
+	n = strings.ReplaceAll(n, " ", "")
+	for i, imp := range InputImpedances {
+		if strings.ToUpper(imp) == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetInput configures the noise gate of the guitar input and, when the hardware has one, its impedance.
+// Settings left to default keep the DefaultInput values.
+func (b *Builder) SetInput(cfg InputConfig, hardware string) {
+	cfg = cfg.Over(DefaultInput)
+	if input := nestedMap(b.section("dsp0"), "inputA"); input != nil {
+		input["noiseGate"] = *cfg.NoiseGate
+		input["threshold"] = math.Max(GateThresholdMin, math.Min(GateThresholdMax, cfg.Threshold))
+		input["decay"] = math.Max(GateDecayMin, math.Min(GateDecayMax, cfg.Decay))
+	}
+
+	if cfg.Impedance == "" || !HasGuitarInputZ(hardware) {
+		return
+	}
+	if idx := ImpedanceIndex(cfg.Impedance); idx >= 0 {
+		if global := b.section("global"); global != nil {
+			global["@guitarinputZ"] = idx
+		}
+	}
+}
+
+// HighGain reports whether the placed blocks make a high-gain preset (a drive pedal past 5 or an amp driven
+// past 6), which needs a tighter input gate
+func (b *Builder) HighGain() bool {
+	for path := 0; path < 2; path++ {
+		for key, raw := range b.section(fmt.Sprintf("dsp%d", path)) {
+			block, ok := raw.(map[string]interface{})
+			if !ok || !strings.HasPrefix(key, "block") {
+				continue
+			}
+			model, _ := block["@model"].(string)
+			threshold := 0.0
+			switch CategoryOf(model) {
+			case CategoryDistortion:
+				threshold = 0.5
+			case CategoryAmp:
+				threshold = 0.6
+			default:
+				continue
+			}
+			for _, param := range []string{"Drive", "Gain", "Distortion", "Fuzz", "Sustain"} {
+				if v, ok := block[param].(float64); ok && v >= threshold {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package helix
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuilderSetInput(t *testing.T) {
+	tests := []struct {
+		name          string
+		hardware      string
+		cfg           InputConfig
+		wantGate      bool
+		wantThreshold float64
+		wantDecay     float64
+		wantZ         int
+	}{
+		{"Defaults", "Helix Floor", InputConfig{}, true, -75, 0.15, 0},
+		{"Gate Off", "Helix Floor", InputConfig{NoiseGate: boolPtr(false), Threshold: -40, Decay: 0.3}, false, -40, 0.3, 0},
+		{"Clamped", "Helix LT", InputConfig{NoiseGate: boolPtr(true), Threshold: -120, Decay: 5}, true, GateThresholdMin, GateDecayMax, 0},
+		{"Impedance", "HX Stomp", InputConfig{NoiseGate: boolPtr(true), Impedance: "230kΩ"}, true, -75, 0.15, 3},
+		{"No Guitar In-Z", "HX Effects", InputConfig{NoiseGate: boolPtr(true), Impedance: "1M"}, true, -75, 0.15, 0},
+		{"Unknown Impedance", "Helix Floor", InputConfig{NoiseGate: boolPtr(true), Impedance: "5M"}, true, -75, 0.15, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBuilder(t, IsDualDSP(tt.hardware))
+			b.SetInput(tt.cfg, tt.hardware)
+			tone, _ := b.Preset.tone()
+			input := nestedMap(tone, "dsp0", "inputA")
+			if input["noiseGate"] != tt.wantGate || input["threshold"] != tt.wantThreshold || input["decay"] != tt.wantDecay {
+				t.Errorf("inputA = %v", input)
+			}
+			if z, _ := number(nestedMap(tone, "global")["@guitarinputZ"]); z != tt.wantZ {
+				t.Errorf("@guitarinputZ = %v, want %d", z, tt.wantZ)
+			}
+		})
+	}
+}
+
+func TestBuilderHighGain(t *testing.T) {
+	tests := []struct {
+		name  string
+		plans []BlockPlan
+		want  bool
+	}{
+		{"Clean Amp", []BlockPlan{{Name: "Amp", ModelName: "Brit Plexi Brt", Params: map[string]interface{}{"Drive": 0.3}}}, false},
+		{"Driven Amp", []BlockPlan{{Name: "Amp", ModelName: "Brit Plexi Brt", Params: map[string]interface{}{"Drive": 0.8}}}, true},
+		{"Low Gain Boost", []BlockPlan{{Name: "Boost", ModelName: "Scream 808", Params: map[string]interface{}{"Gain": 0.2}}}, false},
+		{"Drive Pedal", []BlockPlan{{Name: "Drive", ModelName: "Scream 808", Params: map[string]interface{}{"Gain": 0.7}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBuilder(t, true)
+			for _, plan := range tt.plans {
+				if _, err := b.AddBlock(plan); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := b.HighGain(); got != tt.want {
+				t.Errorf("HighGain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInputConfigOver(t *testing.T) {
+	// The Sound Engineer only asks for an impedance: the gate follows the gain
+	var onlyZ InputConfig
+	if err := json.Unmarshal([]byte(`{"impedance": "1M"}`), &onlyZ); err != nil {
+		t.Fatal(err)
+	}
+	got := onlyZ.Over(HighGainInput)
+	if got.NoiseGate == nil || !*got.NoiseGate || got.Threshold != HighGainInput.Threshold || got.Decay != HighGainInput.Decay || got.Impedance != "1M" {
+		t.Errorf("Over() = %+v, want the high-gain gate with a 1M impedance", got)
+	}
+
+	off := InputConfig{NoiseGate: boolPtr(false)}.Over(HighGainInput)
+	if off.NoiseGate == nil || *off.NoiseGate {
+		t.Errorf("Over() should keep an explicit gate off")
+	}
+}
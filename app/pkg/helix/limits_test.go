@@ -0,0 +1,63 @@
+package helix
+
+import (
+	"testing"
+)
+
+func TestSanitizeParam(t *testing.T) {
+	tests := []struct {
+		name       string
+		internalID string
+		key        string
+		val        interface{}
+		want       interface{}
+	}{
+		// Delay Feedback Tests
+		{"Delay Feedback Normal", "HD2_DelayCompulsive", "Feedback", 0.5, 0.5},
+		{"Delay Feedback High", "HD2_DelayCompulsive", "Feedback", 0.9, 0.75},
+		{"Delay Fdbk High", "HD2_DelaySimple", "Fdbk", 0.8, 0.75},
+		{"Legacy Delay Bk High", "HD2_DelayCosmos", "Bk", 1.0, 0.75},
+		{"Not Delay Feedback High", "HD2_AmpUSDeluxe", "Feedback", 0.9, 0.9},
+
+		// Reverb Decay Tests (Regression Check)
+		{"Reverb Decay Normal", "HD2_ReverbHall", "Decay", 0.5, 0.5},
+		{"Reverb Decay High", "HD2_ReverbHall", "Decay", 0.8, 0.7},
+		{"Delay with VerbDecay High", "HD2_DelayTransistor", "VerbDecay", 0.9, 0.7},
+
+		// Unit strings
+		{"Time In Milliseconds", "HD2_DelayTransistorTape", "Time", "350ms", 0.35},
+		{"Mix In Percent", "HD2_DelayTransistorTape", "Mix", "40%", 0.4},
+		{"Knob String", "HD2_AmpBritPlexiBrt", "Treble", "6.5", 0.65},
+
+		// Knob scale fallback for params without a catalog range
+		{"Unknown Range Knob Scale", "HD2_DistScream808", "Drive", 6.0, 0.6},
+		{"Unknown Range Cap", "HD2_DistScream808", "Drive", 40.0, 1.0},
+
+		// Forbidden params keep the model default
+		{"Amp Hum Forbidden", "HD2_AmpBritPlexiBrt", "Hum", 1.0, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := SanitizeParam(&DB, tt.internalID, tt.key, tt.val); got != tt.want {
+				t.Errorf("SanitizeParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeForbiddenWithoutRange(t *testing.T) {
+	// Unknown to the catalog: no range and no default, the param must be dropped
+	if v, ok := SanitizeParam(&DB, "HD2_AmpFutureAmp", "Hum", 1.0); ok {
+		t.Errorf("SanitizeParam() = %v, want the forbidden param dropped", v)
+	}
+
+	b := newTestBuilder(t, true)
+	amp, err := b.AddBlock(BlockPlan{Name: "Amp", ModelName: "Brit Plexi Brt", Params: map[string]interface{}{"Hum": 1.0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hum, _ := amp.Entry.Param("Hum"); b.block(amp)["Hum"] != hum.Default {
+		t.Errorf("Hum = %v, want the model default %v", b.block(amp)["Hum"], hum.Default)
+	}
+}
@@ -0,0 +1,68 @@
+package helix
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// MetadataKey is the section of data.meta holding the HelAIx metadata. HX Edit ignores unknown meta keys.
+const MetadataKey = "helaix"
+
+// Metadata describes where a generated preset comes from, so presets can be searched and audited later
+type Metadata struct {
+	Author      string   `json:"author,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Genre       string   `json:"genre,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Prompt      string   `json:"prompt,omitempty"`   // Original request of the user
+	Rig         string   `json:"rig,omitempty"`      // Summary of the rig designed by the Sound Engineer
+	Model       string   `json:"model,omitempty"`    // AI model that built the preset (may be a fallback)
+	Version     string   `json:"version,omitempty"`  // HelAIx version
+	Firmware    string   `json:"firmware,omitempty"` // Target Helix firmware
+	Hardware    string   `json:"hardware,omitempty"`
+}
+
+// SetMetadata writes the metadata to data.meta, replacing any previous one
+func (p *Preset) SetMetadata(m Metadata) {
+	meta := childMap(map[string]interface{}(*p), "data", "meta")
+	if meta == nil {
+		return
+	}
+	m.Tags = normalizeTags(m.Tags)
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	var section map[string]interface{}
+	if json.Unmarshal(raw, &section) == nil {
+		meta[MetadataKey] = section
+	}
+}
+
+// Metadata returns the metadata of the preset, if it has one
+func (p Preset) Metadata() (Metadata, bool) {
+	var m Metadata
+	section := nestedMap(map[string]interface{}(p), "data", "meta", MetadataKey)
+	if section == nil {
+		return m, false
+	}
+	raw, err := json.Marshal(section)
+	if err != nil || json.Unmarshal(raw, &m) != nil {
+		return m, false
+	}
+	return m, true
+}
+
+// normalizeTags lowercases and trims the tags, dropping empty ones and duplicates
+func normalizeTags(tags []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package helix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPresetMetadata(t *testing.T) {
+	p, err := NewTemplatePreset("Meta Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.Metadata(); ok {
+		t.Fatal("Metadata() found metadata on the template")
+	}
+
+	p.SetMetadata(Metadata{
+		Author: "Jane",
+		Genre:  "Blues",
+		Tags:   []string{" Blues", "crunch", "blues", ""},
+		Prompt: "SRV Texas Flood",
+		Model:  "gemini-2.5-flash",
+	})
+	got, ok := p.Metadata()
+	if !ok {
+		t.Fatal("Metadata() found no metadata")
+	}
+	want := Metadata{Author: "Jane", Genre: "Blues", Tags: []string{"blues", "crunch"}, Prompt: "SRV Texas Flood", Model: "gemini-2.5-flash"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Metadata() = %+v, want %+v", got, want)
+	}
+
+	// The HX Edit fields of meta are kept, and the preset stays valid
+	meta := nestedMap(map[string]interface{}(*p), "data", "meta")
+	if meta["name"] != "Meta Test" || meta["application"] != "HX Edit" {
+		t.Errorf("meta = %v", meta)
+	}
+	if diags := Validate(p, "Helix Floor"); HasErrors(diags) {
+		t.Errorf("Validate() = %+v", diags)
+	}
+}
+
+func TestPresetRemoveUIData(t *testing.T) {
+	p, err := NewTemplatePreset("Old Chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetMetadata(Metadata{Prompt: "SRV Texas Flood"})
+	meta := nestedMap(map[string]interface{}(*p), "data", "meta")
+	meta["dsp_map"] = map[string]interface{}{"HD2_AmpBritPlexiBrt": 35.0}
+	meta["variax_type"] = "jtv"
+	meta["generated_by"] = "gemini-2.5-flash"
+
+	p.RemoveUIData()
+	for _, key := range []string{"dsp_map", "variax_type", "generated_by"} {
+		if _, ok := meta[key]; ok {
+			t.Errorf("meta still has %q", key)
+		}
+	}
+	if meta["name"] != "Old Chat" || meta[MetadataKey] == nil {
+		t.Errorf("meta = %v, want name and metadata kept", meta)
+	}
+}
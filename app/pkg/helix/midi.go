@@ -0,0 +1,46 @@
+package helix
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ControllerMIDICC is the controller ID of a parameter driven by incoming MIDI CC (its number in "@cc")
+const ControllerMIDICC = 18
+
+// ErrInvalidMIDI is returned for CCs out of the MIDI range or that Helix reserves for itself
+var ErrInvalidMIDI = errors.New("invalid MIDI message")
+
+// MIDIAssignment lets an incoming MIDI CC drive a block parameter between min and max
+type MIDIAssignment struct {
+	Param string  `json:"param"`
+	CC    int     `json:"cc"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+}
+
+// reservedCC lists the CCs Helix answers globally (bank select, expression pedals, footswitches, looper,
+// tap tempo, tuner, snapshots): they cannot be assigned to parameters
+var reservedCC = map[int]bool{0: true, 1: true, 2: true, 3: true, 32: true}
+
+func init() {
+	for cc := 49; cc <= 71; cc++ {
+		reservedCC[cc] = true
+	}
+}
+
+// AssignMIDICC lets an incoming MIDI CC drive a block parameter. Min and max go through the safety rules.
+func (b *Builder) AssignMIDICC(block PlacedBlock, a MIDIAssignment) error {
+	if a.CC < 0 || a.CC > 127 || reservedCC[a.CC] {
+		return fmt.Errorf("%w: CC %d is reserved by Helix", ErrInvalidMIDI, a.CC)
+	}
+	key := block.Entry.ParamKey(a.Param)
+	if _, ok := block.Entry.Param(key); !ok {
+		return fmt.Errorf("%s has no parameter %q", block.Entry.Name, a.Param)
+	}
+	b.AssignController(block, key, Controller{ID: ControllerMIDICC, Min: a.Min, Max: a.Max})
+	if ctrl := nestedMap(b.section("controller"), fmt.Sprintf("dsp%d", block.Path), block.Key, key); ctrl != nil {
+		ctrl["@cc"] = a.CC
+	}
+	return nil
+}
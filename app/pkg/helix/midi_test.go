@@ -0,0 +1,42 @@
+package helix
+
+import (
+	"testing"
+)
+
+func TestBuilderAssignMIDICC(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       MIDIAssignment
+		wantErr bool
+	}{
+		{"Delay Mix", MIDIAssignment{Param: "Mix", CC: 21, Min: 0, Max: 0.6}, false},
+		{"Reserved CC", MIDIAssignment{Param: "Mix", CC: 64, Min: 0, Max: 1}, true},
+		{"Out Of Range", MIDIAssignment{Param: "Mix", CC: 130, Min: 0, Max: 1}, true},
+		{"Unknown Param", MIDIAssignment{Param: "Wobble", CC: 21, Min: 0, Max: 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBuilder(t, true)
+			block, err := b.AddBlock(BlockPlan{Name: "Delay", ModelName: "Simple Delay"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = b.AssignMIDICC(block, tt.a)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AssignMIDICC() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			tone, _ := b.Preset.tone()
+			ctrl := nestedMap(tone, "controller", "dsp0", block.Key, "Mix")
+			if ctrl["@controller"] != ControllerMIDICC || ctrl["@cc"] != tt.a.CC || ctrl["@max"] != tt.a.Max {
+				t.Errorf("controller = %v", ctrl)
+			}
+			if diags := Validate(b.Preset, "Helix Floor"); HasErrors(diags) {
+				t.Errorf("Validate() = %+v", diags)
+			}
+		})
+	}
+}
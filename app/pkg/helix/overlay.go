@@ -0,0 +1,223 @@
+package helix
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ImportReport summarizes an import of .hlx files into the user overlay catalog
+type ImportReport struct {
+	FilesScanned int      `json:"files_scanned"`
+	FilesFailed  []string `json:"files_failed,omitempty"`
+	Learned      []string `json:"learned"` // Internal IDs of the new models
+}
+
+// LearnFromHLXFolder scans a folder of HX Edit exports and returns catalog entries
+// for every "@model" unknown to db, built from the block params and controller ranges found in the files.
+func LearnFromHLXFolder(dir string, db *CatalogDB) ([]CatalogEntry, ImportReport, error) {
+	db.EnsureLoaded()
+	report := ImportReport{Learned: []string{}}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.hlx"))
+	if err != nil {
+		return nil, report, err
+	}
+	sort.Strings(files)
+
+	learned := make(map[string]*CatalogEntry)
+	var order []string
+	for _, f := range files {
+		report.FilesScanned++
+		data, err := os.ReadFile(f)
+		if err != nil {
+			report.FilesFailed = append(report.FilesFailed, filepath.Base(f))
+			continue
+		}
+		var p Preset
+		if err := json.Unmarshal(data, &p); err != nil {
+			report.FilesFailed = append(report.FilesFailed, filepath.Base(f))
+			continue
+		}
+		tone, ok := p.tone()
+		if !ok {
+			report.FilesFailed = append(report.FilesFailed, filepath.Base(f))
+			continue
+		}
+
+		for _, dspKey := range []string{"dsp0", "dsp1"} {
+			dsp, _ := tone[dspKey].(map[string]interface{})
+			ctrls := nestedMap(tone, "controller", dspKey)
+			for blockKey, raw := range dsp {
+				block, ok := raw.(map[string]interface{})
+				if !ok || !(strings.HasPrefix(blockKey, "block") || strings.HasPrefix(blockKey, "cab")) {
+					continue
+				}
+				model, _ := block["@model"].(string)
+				if model == "" {
+					continue
+				}
+				if _, known := db.FindByID(model); known {
+					continue
+				}
+
+				entry, seen := learned[model]
+				if !seen {
+					entry = &CatalogEntry{
+						InternalName: model,
+						Name:         model,
+						BasedOn:      "Unknown",
+						Data: map[string]interface{}{
+							"Defaults":        map[string]interface{}{},
+							"Controller_Dict": map[string]interface{}{},
+							"SnapshotParams":  map[string]interface{}{},
+						},
+					}
+					learned[model] = entry
+					order = append(order, model)
+				}
+				mergeLearnedBlock(entry, block, nestedMap(ctrls, blockKey))
+			}
+		}
+	}
+
+	var entries []CatalogEntry
+	correspondance := loadCorrespondance()
+	for _, model := range order {
+		e := learned[model]
+		classify(e, correspondance)
+		entries = append(entries, *e)
+		report.Learned = append(report.Learned, model)
+	}
+	return entries, report, nil
+}
+
+// mergeLearnedBlock records the params of one occurrence of a block (first occurrence wins for defaults)
+func mergeLearnedBlock(e *CatalogEntry, block, ctrls map[string]interface{}) {
+	defaults := e.Data["Defaults"].(map[string]interface{})
+	dict := e.Data["Controller_Dict"].(map[string]interface{})
+	snapParams := e.Data["SnapshotParams"].(map[string]interface{})
+
+	for k, v := range block {
+		if k == "@name" {
+			continue // Block label, not a model param
+		}
+		if _, exists := defaults[k]; exists {
+			continue
+		}
+		switch k {
+		case "@position", "@path":
+			defaults[k] = 0
+		case "@enabled":
+			defaults[k] = true
+		default:
+			defaults[k] = v
+		}
+		if !strings.HasPrefix(k, "@") {
+			snapParams[k] = map[string]interface{}{"@fs_enabled": false, "@value": v}
+		}
+	}
+
+	for param, raw := range ctrls {
+		c, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, exists := dict[param]; exists {
+			continue
+		}
+		dict[param] = map[string]interface{}{
+			"@controller":       19,
+			"@max":              c["@max"],
+			"@min":              c["@min"],
+			"@snapshot_disable": false,
+		}
+	}
+}
+
+// Merge adds entries unknown to the catalog (the embedded entries always win). It returns the number added.
+// It is safe to call while the catalog is being read: Entries is replaced, never appended in place.
+func (db *CatalogDB) Merge(entries []CatalogEntry) int {
+	db.EnsureLoaded()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	added := 0
+	merged := db.Entries[:len(db.Entries):len(db.Entries)]
+	for _, e := range entries {
+		if _, exists := db.byInternalName[e.InternalName]; exists {
+			continue
+		}
+		merged = append(merged, e)
+		db.byInternalName[e.InternalName] = e
+		if e.Name != "" {
+			if _, exists := db.byName[strings.ToLower(e.Name)]; !exists {
+				db.byName[strings.ToLower(e.Name)] = e
+			}
+		}
+		added++
+	}
+	db.Entries = merged
+	return added
+}
+
+// LoadOverlay reads the user overlay catalog. A missing file is not an error.
+func LoadOverlay(path string) ([]CatalogEntry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []CatalogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid overlay catalog %s: %v", path, err)
+	}
+	correspondance := loadCorrespondance()
+	for i := range entries {
+		classify(&entries[i], correspondance)
+	}
+	return entries, nil
+}
+
+// SaveOverlay merges new entries into the overlay file (existing entries are kept) and writes it back
+func SaveOverlay(path string, entries []CatalogEntry) error {
+	existing, err := LoadOverlay(path)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool)
+	for _, e := range existing {
+		known[e.InternalName] = true
+	}
+	for _, e := range entries {
+		if !known[e.InternalName] {
+			existing = append(existing, e)
+			known[e.InternalName] = true
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// nestedMap walks a chain of map keys, returning nil if any level is missing
+func nestedMap(m map[string]interface{}, keys ...string) map[string]interface{} {
+	for _, k := range keys {
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		m = next
+	}
+	return m
+}
@@ -0,0 +1,109 @@
+package helix
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestLearnFromHLXFolder(t *testing.T) {
+	dir := t.TempDir()
+	hlx := `{
+		"data": {
+			"tone": {
+				"dsp0": {
+					"block0": {"@model": "HD2_DistScream808", "@enabled": true, "Gain": 0.5},
+					"block1": {"@model": "HD2_DelayFutureEcho", "@enabled": false, "@position": 1, "@type": 7, "Time": 0.5, "Mix": 0.3}
+				},
+				"controller": {
+					"dsp0": {
+						"block1": {"Time": {"@controller": 19, "@min": 0.0, "@max": 4.0, "@snapshot_disable": false}}
+					}
+				}
+			}
+		}
+	}`
+	if err := os.WriteFile(filepath.Join(dir, "future.hlx"), []byte(hlx), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.hlx"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, report, err := LearnFromHLXFolder(dir, &DB)
+	if err != nil {
+		t.Fatalf("LearnFromHLXFolder() error: %v", err)
+	}
+	if report.FilesScanned != 2 || len(report.FilesFailed) != 1 {
+		t.Errorf("report = %+v, want 2 scanned / 1 failed", report)
+	}
+	if len(entries) != 1 || entries[0].InternalName != "HD2_DelayFutureEcho" {
+		t.Fatalf("learned = %+v, want only HD2_DelayFutureEcho", report.Learned)
+	}
+
+	e := entries[0]
+	if e.Category != CategoryDelay {
+		t.Errorf("Category = %s, want %s", e.Category, CategoryDelay)
+	}
+	p, ok := e.Param("Time")
+	if !ok || p.Max != 4.0 || p.Unit != UnitSeconds {
+		t.Errorf("Param(Time) = %+v, want max 4 in seconds", p)
+	}
+	if e.Data["Defaults"].(map[string]interface{})["@position"] != 0 {
+		t.Errorf("learned defaults should reset @position")
+	}
+
+	t.Run("Overlay Round Trip", func(t *testing.T) {
+		path := filepath.Join(dir, "overlay", "catalog_overlay.json")
+		if err := SaveOverlay(path, entries); err != nil {
+			t.Fatalf("SaveOverlay() error: %v", err)
+		}
+		loaded, err := LoadOverlay(path)
+		if err != nil || len(loaded) != 1 {
+			t.Fatalf("LoadOverlay() = %d entries, %v", len(loaded), err)
+		}
+
+		db := &CatalogDB{}
+		if added := db.Merge(loaded); added != 1 {
+			t.Errorf("Merge() added %d, want 1", added)
+		}
+		if _, ok := db.FindByID("HD2_DelayFutureEcho"); !ok {
+			t.Errorf("merged entry not found")
+		}
+		if added := db.Merge(loaded); added != 0 {
+			t.Errorf("second Merge() added %d, want 0", added)
+		}
+	})
+}
+
+func TestMergeWhileReading(t *testing.T) {
+	db := &CatalogDB{}
+	db.EnsureLoaded()
+	before := len(db.All())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				db.FindByID("HD2_DistScream808")
+				db.FindByRealName("Scream 808")
+				db.Search(SearchQuery{Text: "scream", Limit: 5})
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		db.Merge([]CatalogEntry{{InternalName: fmt.Sprintf("HD2_Learned%d", i), Name: fmt.Sprintf("Learned %d", i)}})
+	}
+	wg.Wait()
+
+	if got := len(db.All()); got != before+20 {
+		t.Errorf("len(All()) = %d, want %d", got, before+20)
+	}
+	if _, ok := db.FindByID("HD2_Learned19"); !ok {
+		t.Errorf("merged entry not found")
+	}
+}
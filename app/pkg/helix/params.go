@@ -0,0 +1,273 @@
+package helix
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Parameter value types
+const (
+	ParamFloat = "float"
+	ParamInt   = "int"
+	ParamBool  = "bool"
+)
+
+// Parameter units
+const (
+	UnitNone      = ""
+	UnitKnob      = "knob" // 0.0-1.0 internally, 0-10 on the hardware
+	UnitPercent   = "%"    // 0.0-1.0 internally, 0-100% on the hardware
+	UnitDB        = "dB"
+	UnitHz        = "Hz"
+	UnitSeconds   = "s"
+	UnitNote      = "note"
+	UnitSemitones = "semitones"
+	UnitCents     = "cents"
+)
+
+// NoteDivisions lists the tempo-sync note values in "SyncSelect" order. The catalog range of SyncSelect is 1-19
+// (index 1 = whole note, default 6 = quarter note); index 0 is unused.
+var NoteDivisions = []string{
+	"", "1/1", "1/2.", "1/2", "1/2T", "1/4.", "1/4", "1/4T", "1/8.", "1/8",
+	"1/8T", "1/16.", "1/16", "1/16T", "1/32.", "1/32", "1/32T", "1/64.", "1/64", "1/64T",
+}
+
+// ParamInfo describes a single block parameter
+type ParamInfo struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"` // float, int, bool
+	Min     float64     `json:"min"`
+	Max     float64     `json:"max"`
+	Unit    string      `json:"unit"`
+	Default interface{} `json:"default"`
+}
+
+// Params returns the metadata of every (non technical) parameter of the model, sorted by name
+func (e CatalogEntry) Params() []ParamInfo {
+	defaults, _ := e.Data["Defaults"].(map[string]interface{})
+	var list []ParamInfo
+	for name := range defaults {
+		if strings.HasPrefix(name, "@") {
+			continue
+		}
+		if p, ok := e.Param(name); ok {
+			list = append(list, p)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Param returns the metadata of a single parameter.
+// Ranges come from the Controller_Dict (@min/@max), types from the default value.
+func (e CatalogEntry) Param(name string) (ParamInfo, bool) {
+	defaults, _ := e.Data["Defaults"].(map[string]interface{})
+	def, ok := defaults[name]
+	if !ok || strings.HasPrefix(name, "@") {
+		return ParamInfo{}, false
+	}
+
+	p := ParamInfo{Name: name, Default: def, Type: ParamFloat, Min: 0, Max: 1}
+	if _, isBool := def.(bool); isBool {
+		p.Type = ParamBool
+		return p, true
+	}
+
+	if ctrls, ok := e.Data["Controller_Dict"].(map[string]interface{}); ok {
+		if c, ok := ctrls[name].(map[string]interface{}); ok {
+			// Some switches are declared with boolean ranges
+			if _, isBool := c["@min"].(bool); isBool {
+				p.Type = ParamBool
+				return p, true
+			}
+			if v, ok := c["@min"].(float64); ok {
+				p.Min = v
+			}
+			if v, ok := c["@max"].(float64); ok {
+				p.Max = v
+			}
+		}
+	}
+
+	p.Unit = inferUnit(name, p.Min, p.Max)
+	if d, ok := def.(float64); ok && isIntegral(d) && isIntegral(p.Min) && isIntegral(p.Max) &&
+		p.Max-p.Min >= 2 && name != "Distance" && (p.Unit == UnitNone || p.Unit == UnitNote || p.Unit == UnitSemitones) {
+		p.Type = ParamInt
+	}
+	return p, true
+}
+
+// Normalize converts a value proposed by the AI to the internal scale of the parameter and clamps it to its range.
+// It understands 0-10 knob and 0-100% scales for normalized params, and milliseconds for time params.
+func (p ParamInfo) Normalize(v interface{}) interface{} {
+	if p.Type == ParamBool {
+		switch val := v.(type) {
+		case bool:
+			return val
+		case float64:
+			return val >= 0.5
+		case string:
+			s := strings.ToLower(strings.TrimSpace(val))
+			return s == "on" || s == "true" || s == "yes" || s == "1"
+		}
+		return p.Default
+	}
+
+	val, ok := v.(float64)
+	if !ok {
+		if s, isString := v.(string); isString && p.Unit == UnitNote {
+			if idx := NoteIndex(s); idx > 0 {
+				return float64(idx)
+			}
+		}
+		return v
+	}
+
+	isNormalized := p.Min == 0 && p.Max == 1
+	switch {
+	case isNormalized && val > 1.0 && val <= 10.0:
+		val = val / 10.0 // 0-10 knob scale
+	case isNormalized && val > 10.0 && val <= 100.0:
+		val = val / 100.0 // Percentage
+	case p.Unit == UnitSeconds && val > p.Max && val/1000.0 <= p.Max:
+		val = val / 1000.0 // Milliseconds
+	}
+
+	if p.Type == ParamInt {
+		val = math.Round(val)
+	}
+	return math.Max(p.Min, math.Min(p.Max, val))
+}
+
+// Display renders a value the way the hardware shows it (e.g. "6.5", "40%", "-3.0 dB", "1/8.", "375 ms")
+func (p ParamInfo) Display(v interface{}) string {
+	if b, ok := v.(bool); ok {
+		if b {
+			return "On"
+		}
+		return "Off"
+	}
+	val, ok := v.(float64)
+	if !ok {
+		return fmt.Sprintf("%v", v)
+	}
+
+	switch p.Unit {
+	case UnitKnob:
+		return fmt.Sprintf("%.1f", val*10)
+	case UnitPercent:
+		return fmt.Sprintf("%.0f%%", val*100)
+	case UnitDB:
+		return fmt.Sprintf("%.1f dB", val)
+	case UnitHz:
+		if val >= p.Max && p.Max > 20000 {
+			return "Off" // High cut fully open
+		}
+		if val <= p.Min && p.Min < 20 && p.Min > 0 {
+			return "Off" // Low cut fully closed
+		}
+		if val >= 1000 {
+			return fmt.Sprintf("%.1f kHz", val/1000)
+		}
+		return fmt.Sprintf("%.1f Hz", val)
+	case UnitSeconds:
+		if val < 1 {
+			return fmt.Sprintf("%.0f ms", val*1000)
+		}
+		return fmt.Sprintf("%.2f s", val)
+	case UnitNote:
+		if i := int(val); i > 0 && i < len(NoteDivisions) {
+			return NoteDivisions[i]
+		}
+	case UnitSemitones:
+		return fmt.Sprintf("%+.0f st", val)
+	case UnitCents:
+		return fmt.Sprintf("%+.0f ct", val)
+	}
+	if p.Type == ParamInt {
+		return fmt.Sprintf("%.0f", val)
+	}
+	return fmt.Sprintf("%.2f", val)
+}
+
+// NoteIndex returns the SyncSelect index of a note division ("1/8.", "dotted eighth", "1/4T"), or -1 if unknown
+func NoteIndex(note string) int {
+	n := strings.ToLower(strings.TrimSpace(note))
+	n = strings.ReplaceAll(n, " note", "")
+	words := map[string]string{"whole": "1/1", "half": "1/2", "quarter": "1/4", "eighth": "1/8", "sixteenth": "1/16", "thirty-second": "1/32", "sixty-fourth": "1/64"}
+	for w, frac := range words {
+		switch {
+		case strings.HasPrefix(n, "dotted ") && strings.TrimPrefix(n, "dotted ") == w:
+			n = frac + "."
+		case strings.HasSuffix(n, " triplet") && strings.TrimSuffix(n, " triplet") == w:
+			n = frac + "T"
+		case n == w:
+			n = frac
+		}
+	}
+	n = strings.ReplaceAll(n, "t", "T")
+	for i, d := range NoteDivisions {
+		if i > 0 && d == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// inferUnit guesses the unit of a parameter from its name and range
+func inferUnit(name string, min, max float64) string {
+	n := strings.ToLower(name)
+	isNormalized := min == 0 && max == 1
+	has := func(words ...string) bool {
+		for _, w := range words {
+			if strings.Contains(n, w) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(n, "syncselect"):
+		return UnitNote
+	case has("cents"):
+		return UnitCents
+	case has("interval", "pitch", "shift") && min < 0:
+		return UnitSemitones
+	case has("cut", "freq", "fc", "damping") && max >= 100:
+		return UnitHz
+	case has("speed", "rate") && !isNormalized:
+		return UnitHz
+	case has("time", "predelay", "decay", "attack", "release") && !isNormalized:
+		return UnitSeconds
+	case min < 0 && has("level", "gain", "threshold", "headroom", "treble", "bass", "mid", "boost", "trim"):
+		return UnitDB
+	case isNormalized && has("mix", "feedback", "fdbk", "depth", "intensity", "blend", "level"):
+		return UnitPercent
+	case isNormalized:
+		return UnitKnob
+	}
+	return UnitNone
+}
+
+func isIntegral(v float64) bool {
+	return v == math.Trunc(v)
+}
+
+// FormatParams renders the given block params with their units, keyed by param name.
+// Technical "@" keys and unknown params are skipped.
+func (db *CatalogDB) FormatParams(modelID string, params map[string]interface{}) map[string]string {
+	out := make(map[string]string)
+	e, ok := db.FindByID(modelID)
+	if !ok {
+		return out
+	}
+	for name, v := range params {
+		if p, ok := e.Param(name); ok {
+			out[name] = p.Display(v)
+		}
+	}
+	return out
+}
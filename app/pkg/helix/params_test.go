@@ -0,0 +1,93 @@
+package helix
+
+import (
+	"testing"
+)
+
+func TestParamNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		param string
+		val   interface{}
+		want  interface{}
+	}{
+		{"Knob 0-10 Scale", "HD2_AmpA30FawnBrt", "Drive", 6.5, 0.65},
+		{"Knob Percent Scale", "HD2_AmpA30FawnBrt", "Drive", 40.0, 0.4},
+		{"Knob Clamp", "HD2_AmpA30FawnBrt", "Drive", 500.0, 1.0},
+		{"Delay Time Milliseconds", "HD2_DelayAdriaticDelay", "Time", 375.0, 0.375},
+		{"Delay Time Seconds", "HD2_DelayAdriaticDelay", "Time", 0.5, 0.5},
+		{"Note Division Name", "HD2_DelayAdriaticDelay", "SyncSelect1", "dotted eighth", 8.0},
+		{"Note Division Rounded", "HD2_DelayAdriaticDelay", "SyncSelect1", 7.6, 8.0},
+		{"Bool From String", "HD2_DelayAdriaticDelay", "TempoSync1", "on", true},
+		{"dB Clamp", "HD2_DelayAdriaticDelay", "Level", -80.0, -60.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, ok := DB.FindByID(tt.model)
+			if !ok {
+				t.Fatalf("%s not found in catalog", tt.model)
+			}
+			p, ok := e.Param(tt.param)
+			if !ok {
+				t.Fatalf("%s has no param %s", tt.model, tt.param)
+			}
+			if got := p.Normalize(tt.val); got != tt.want {
+				t.Errorf("Normalize(%v) = %v, want %v", tt.val, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParamDisplay(t *testing.T) {
+	got := DB.FormatParams("HD2_DelayAdriaticDelay", map[string]interface{}{
+		"Time":        0.375,
+		"SyncSelect1": 8.0,
+		"Mix":         0.3,
+		"Level":       -3.0,
+		"TempoSync1":  false,
+		"@model":      "HD2_DelayAdriaticDelay",
+	})
+	want := map[string]string{
+		"Time":        "375 ms",
+		"SyncSelect1": "1/8.",
+		"Mix":         "30%",
+		"Level":       "-3.0 dB",
+		"TempoSync1":  "Off",
+	}
+	if len(got) != len(want) {
+		t.Errorf("FormatParams() returned %d values, want %d: %v", len(got), len(want), got)
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("FormatParams()[%s] = %q, want %q", k, got[k], w)
+		}
+	}
+}
+
+func TestNoteDivisionsMatchCatalog(t *testing.T) {
+	DB.EnsureLoaded()
+	checked := 0
+	for _, e := range DB.Entries {
+		for _, p := range e.Params() {
+			if p.Unit != UnitNote || p.Max <= 1 { // Models without a declared range
+				continue
+			}
+			checked++
+			if p.Min != 1 || int(p.Max) != len(NoteDivisions)-1 {
+				t.Errorf("%s %s range = %g-%g, want 1-%d", e.InternalName, p.Name, p.Min, p.Max, len(NoteDivisions)-1)
+			}
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no tempo-sync parameter found in the catalog")
+	}
+
+	// Catalog default of the delays
+	entry, _ := DB.FindByID("HD2_DelayAdriaticDelay")
+	info, _ := entry.Param("SyncSelect1")
+	if got := info.Display(info.Default); got != "1/4" {
+		t.Errorf("default SyncSelect1 = %q, want 1/4", got)
+	}
+}
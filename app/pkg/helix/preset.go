@@ -14,6 +14,17 @@ var templateJSON []byte
 // to strictly type without risk of missing fields.
 type Preset map[string]interface{}
 
+// uiMetaKeys are the meta keys older HelAIx versions wrote for the UI. HX Edit never writes them.
+var uiMetaKeys = []string{"dsp_map", "variax_type", "generated_by"}
+
+// RemoveUIData deletes the UI-only keys presets of older versions carry, so the file matches an HX Edit export
+func (p *Preset) RemoveUIData() {
+	meta := nestedMap(map[string]interface{}(*p), "data", "meta")
+	for _, key := range uiMetaKeys {
+		delete(meta, key)
+	}
+}
+
 // NewTemplatePreset returns a fresh copy of the template preset
 // with the Name updated and Effects blocks/controllers/footswitches cleared.
 func NewTemplatePreset(name string) (*Preset, error) {
@@ -0,0 +1,160 @@
+package helix
+
+import (
+	_ "embed"
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/safety_rules.json
+var safetyRulesJSON []byte
+
+// Safety rule actions
+const (
+	ActionClamp  = "clamp"  // Keep the value within min/max
+	ActionScale  = "scale"  // Divide values above "above" by "divisor", then clamp
+	ActionForbid = "forbid" // Always use the model default
+)
+
+// SafetyRule limits the values of matching parameters. A rule matches when every non-empty criterion matches.
+type SafetyRule struct {
+	ID               string   `json:"id"`
+	Description      string   `json:"description,omitempty"`
+	Models           []string `json:"models,omitempty"`            // Internal IDs or ID prefixes, e.g. "HD2_Reverb"
+	Categories       []string `json:"categories,omitempty"`        // Block categories
+	Params           []string `json:"params,omitempty"`            // Parameter names (case-insensitive)
+	ParamsContaining []string `json:"params_containing,omitempty"` // Parameter name substrings (case-insensitive)
+	UnknownRange     bool     `json:"unknown_range,omitempty"`     // Only params without a catalog range
+	Action           string   `json:"action"`
+	Min              *float64 `json:"min,omitempty"`
+	Max              *float64 `json:"max,omitempty"`
+	Above            *float64 `json:"above,omitempty"`
+	Divisor          float64  `json:"divisor,omitempty"`
+}
+
+var (
+	safetyRules     []SafetyRule
+	safetyRulesOnce sync.Once
+)
+
+// SafetyRules returns the embedded rule set, in the order it is applied
+func SafetyRules() []SafetyRule {
+	safetyRulesOnce.Do(func() {
+		var root struct {
+			Rules []SafetyRule `json:"rules"`
+		}
+		if err := json.Unmarshal(safetyRulesJSON, &root); err != nil {
+			panic("Failed to load embedded safety_rules.json: " + err.Error())
+		}
+		safetyRules = root.Rules
+	})
+	return safetyRules
+}
+
+// SanitizeParam makes a value proposed by the AI or the user safe to write: unit strings ("350ms", "40%")
+// are parsed, the value is normalized to the catalog range of the parameter, then the safety rules apply.
+// It is used for base params, snapshot values and controller min/max alike, with the catalog of the preset.
+// ok is false for a forbidden param without a model default: it must not be written at all.
+func SanitizeParam(db *CatalogDB, internalID, k string, v interface{}) (interface{}, bool) {
+	if s, ok := v.(string); ok {
+		if f, ok := parseUnitValue(s); ok {
+			v = f
+		}
+	}
+
+	entry, known := db.FindByID(internalID)
+	info, hasInfo := ParamInfo{}, false
+	if known {
+		info, hasInfo = entry.Param(k)
+	}
+	if hasInfo {
+		v = info.Normalize(v)
+	}
+
+	category := inferCategory(internalID)
+	if known {
+		category = entry.Category
+	}
+	for _, r := range SafetyRules() {
+		if !r.matches(internalID, category, k, hasInfo) {
+			continue
+		}
+		if r.Action == ActionForbid {
+			return info.Default, hasInfo
+		}
+		if val, ok := v.(float64); ok {
+			v = r.apply(val)
+		}
+	}
+	return v, true
+}
+
+func (r SafetyRule) matches(internalID, category, param string, hasInfo bool) bool {
+	if r.UnknownRange && hasInfo {
+		return false
+	}
+	if len(r.Models) > 0 && !anyMatch(r.Models, func(m string) bool { return strings.HasPrefix(internalID, m) }) {
+		return false
+	}
+	if len(r.Categories) > 0 && !anyMatch(r.Categories, func(c string) bool { return c == category }) {
+		return false
+	}
+	if len(r.Params) > 0 && !anyMatch(r.Params, func(p string) bool { return strings.EqualFold(p, param) }) {
+		return false
+	}
+	lowParam := strings.ToLower(param)
+	if len(r.ParamsContaining) > 0 && !anyMatch(r.ParamsContaining, func(p string) bool { return strings.Contains(lowParam, p) }) {
+		return false
+	}
+	return true
+}
+
+func (r SafetyRule) apply(val float64) float64 {
+	if r.Action == ActionScale && r.Divisor != 0 && r.Above != nil && val > *r.Above {
+		val = val / r.Divisor
+	}
+	if r.Min != nil {
+		val = math.Max(*r.Min, val)
+	}
+	if r.Max != nil {
+		val = math.Min(*r.Max, val)
+	}
+	return val
+}
+
+func anyMatch(list []string, match func(string) bool) bool {
+	for _, s := range list {
+		if match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+var unitValueRe = regexp.MustCompile(`^([-+]?\d*\.?\d+)\s*(ms|s|sec|%|db|hz|khz)?$`)
+
+// parseUnitValue reads a number written with a unit, converted to the internal scale:
+// seconds for times, 0.0-1.0 for percentages, Hz for frequencies
+func parseUnitValue(s string) (float64, bool) {
+	m := unitValueRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return 0, false
+	}
+	val, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	switch m[2] {
+	case "ms":
+		val /= 1000
+	case "%":
+		val /= 100
+	case "khz":
+		val *= 1000
+	}
+	return val, true
+}
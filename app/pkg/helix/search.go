@@ -0,0 +1,245 @@
+package helix
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SearchQuery describes a catalog lookup coming from the UI
+type SearchQuery struct {
+	Text     string  `json:"text"`     // Free text matched against Name, BasedOn and InternalName (typo tolerant)
+	Category string  `json:"category"` // Optional category facet, e.g. "amp", "delay"
+	MaxDSP   float64 `json:"max_dsp"`  // Optional upper bound on mono DSP cost (0 = no limit)
+	Limit    int     `json:"limit"`    // Maximum number of results (0 = default)
+}
+
+// SearchResult is a lightweight view of a catalog entry (without the block data)
+type SearchResult struct {
+	InternalName string  `json:"internal_name"`
+	Name         string  `json:"name"`
+	BasedOn      string  `json:"based_on"`
+	Category     string  `json:"category"`
+	DSPMono      float64 `json:"dsp_mono"`
+	DSPStereo    float64 `json:"dsp_stereo"`
+	Score        float64 `json:"score"`
+}
+
+const defaultSearchLimit = 50
+
+// Search runs a ranked, typo tolerant search over the catalog.
+// Every word of the query must match (exactly, by prefix, or within a small edit distance)
+// the Name, BasedOn or InternalName of an entry. Name matches rank higher than BasedOn matches.
+func (db *CatalogDB) Search(q SearchQuery) []SearchResult {
+	db.EnsureLoaded()
+
+	terms := tokenize(q.Text)
+	category := strings.ToLower(strings.TrimSpace(q.Category))
+
+	var results []SearchResult
+	for _, e := range db.All() {
+		if category != "" && e.Category != category {
+			continue
+		}
+		if q.MaxDSP > 0 && e.DSPMono > q.MaxDSP {
+			continue
+		}
+
+		score := 1.0 // Facet-only queries keep catalog order
+		if len(terms) > 0 {
+			var ok bool
+			score, ok = scoreEntry(e, terms)
+			if !ok {
+				continue
+			}
+		}
+
+		results = append(results, SearchResult{
+			InternalName: e.InternalName,
+			Name:         e.Name,
+			BasedOn:      e.BasedOn,
+			Category:     e.Category,
+			DSPMono:      e.DSPMono,
+			DSPStereo:    e.DSPStereo,
+			Score:        score,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// Candidates returns up to limit entries of the given categories ranked by similarity with text.
+// Unlike Search, terms are OR-ed: an entry only needs to match one word of the text.
+func (db *CatalogDB) Candidates(text string, categories []string, limit int) []CatalogEntry {
+	db.EnsureLoaded()
+	terms := tokenize(text)
+	if len(terms) == 0 {
+		return nil
+	}
+	allowed := make(map[string]bool)
+	for _, c := range categories {
+		allowed[c] = true
+	}
+
+	type scored struct {
+		entry CatalogEntry
+		score float64
+	}
+	var ranked []scored
+	for _, e := range db.All() {
+		if len(allowed) > 0 && !allowed[e.Category] {
+			continue
+		}
+		nameTokens := tokenize(e.Name)
+		basedOnTokens := tokenize(e.BasedOn)
+		score := 0.0
+		for _, t := range terms {
+			if len(t) < 2 {
+				continue
+			}
+			best := bestTokenScore(t, nameTokens) * 3
+			if s := bestTokenScore(t, basedOnTokens) * 2; s > best {
+				best = s
+			}
+			score += best
+		}
+		if score > 0 {
+			ranked = append(ranked, scored{e, score})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+	var out []CatalogEntry
+	for i := 0; i < len(ranked) && i < limit; i++ {
+		out = append(out, ranked[i].entry)
+	}
+	return out
+}
+
+// scoreEntry returns the relevance of an entry for the given terms.
+// It returns false if any term does not match at all.
+func scoreEntry(e CatalogEntry, terms []string) (float64, bool) {
+	nameTokens := tokenize(e.Name)
+	basedOnTokens := tokenize(e.BasedOn)
+	idTokens := tokenize(e.InternalName)
+
+	total := 0.0
+	for _, t := range terms {
+		best := bestTokenScore(t, nameTokens) * 3
+		if s := bestTokenScore(t, basedOnTokens) * 2; s > best {
+			best = s
+		}
+		if s := bestTokenScore(t, idTokens); s > best {
+			best = s
+		}
+		if best == 0 {
+			return 0, false
+		}
+		total += best
+	}
+
+	// Bonus for a full phrase match on the display name
+	phrase := strings.Join(terms, " ")
+	if strings.EqualFold(strings.Join(nameTokens, " "), phrase) {
+		total += 5
+	} else if strings.Contains(strings.ToLower(e.Name), phrase) {
+		total += 2
+	}
+	return total, true
+}
+
+// bestTokenScore compares a query term against a list of tokens.
+// Exact = 1.0, prefix = 0.8, substring = 0.6, typo within tolerance = 0.4.
+func bestTokenScore(term string, tokens []string) float64 {
+	best := 0.0
+	for _, tok := range tokens {
+		var s float64
+		switch {
+		case tok == term:
+			s = 1.0
+		case strings.HasPrefix(tok, term):
+			s = 0.8
+		case len(term) >= 3 && strings.Contains(tok, term):
+			s = 0.6
+		case levenshtein(term, tok) <= typoTolerance(term):
+			s = 0.4
+		}
+		if s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// typoTolerance returns the maximum edit distance accepted for a term
+func typoTolerance(term string) int {
+	switch n := len(term); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// tokenize lowercases and splits on anything that is not a letter or a digit.
+// CamelCase identifiers (HD2_AmpBritPlexi) are split into their words.
+func tokenize(s string) []string {
+	var tokens []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			tokens = append(tokens, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			flush()
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return tokens
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
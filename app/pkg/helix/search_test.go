@@ -0,0 +1,47 @@
+package helix
+
+import (
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     SearchQuery
+		wantFirst string
+	}{
+		{"Exact Name", SearchQuery{Text: "Brit Plexi Jump"}, "HD2_AmpBritPlexiJump"},
+		{"BasedOn", SearchQuery{Text: "Peavey 5150"}, "HD2_AmpPVPanama"},
+		{"Typo", SearchQuery{Text: "Screem 808"}, "HD2_DistScream808"},
+		{"Category Facet", SearchQuery{Text: "teemah", Category: "distortion"}, "HD2_DistTeemah"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := DB.Search(tt.query)
+			if len(results) == 0 {
+				t.Fatalf("Search(%q) returned no results", tt.query.Text)
+			}
+			if results[0].InternalName != tt.wantFirst {
+				t.Errorf("Search(%q) first = %s, want %s", tt.query.Text, results[0].InternalName, tt.wantFirst)
+			}
+		})
+	}
+
+	t.Run("DSP Facet", func(t *testing.T) {
+		for _, r := range DB.Search(SearchQuery{Category: "amp", MaxDSP: 30}) {
+			if r.DSPMono > 30 {
+				t.Errorf("%s DSP %.1f exceeds max 30", r.InternalName, r.DSPMono)
+			}
+			if r.Category != "amp" {
+				t.Errorf("%s category = %s, want amp", r.InternalName, r.Category)
+			}
+		}
+	})
+
+	t.Run("No Match", func(t *testing.T) {
+		if results := DB.Search(SearchQuery{Text: "zzzzqqqq"}); len(results) != 0 {
+			t.Errorf("expected no results, got %d", len(results))
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package helix
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SetlistEntry is the level of one preset of a setlist, before and after matching
+type SetlistEntry struct {
+	File        string  `json:"file"`
+	Name        string  `json:"name"`
+	EstimatedDB float64 `json:"estimated_db"` // Average of the snapshot estimates, before matching
+	TrimDB      float64 `json:"trim_db"`      // Average correction applied to the output block
+	Error       string  `json:"error,omitempty"`
+}
+
+// SetlistReport summarizes a volume matching of several presets
+type SetlistReport struct {
+	Reference   string         `json:"reference"`
+	ReferenceDB float64        `json:"reference_db"`
+	Presets     []SetlistEntry `json:"presets"`
+}
+
+// PresetLevel is the average estimated level of the snapshots of a preset (0 when nothing is enabled)
+func (db *CatalogDB) PresetLevel(p *Preset) float64 {
+	levels := db.EstimateLevels(p)
+	if len(levels) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, lvl := range levels {
+		sum += lvl.EstimatedDB
+	}
+	return round1(sum / float64(len(levels)))
+}
+
+// MatchLevels balances the snapshots of every preset on the level of presets[reference].
+// The reference preset is left untouched. It returns the reference level.
+func (db *CatalogDB) MatchLevels(presets []*Preset, reference int, opts GainStaging) ([]*LevelResult, float64, error) {
+	if reference < 0 || reference >= len(presets) {
+		return nil, 0, fmt.Errorf("reference preset %d out of range", reference)
+	}
+	opts.TargetDB = db.PresetLevel(presets[reference])
+
+	results := make([]*LevelResult, len(presets))
+	for i, p := range presets {
+		if i == reference {
+			results[i] = &LevelResult{Preset: p, Snapshots: db.EstimateLevels(p)}
+			continue
+		}
+		results[i] = db.NormalizeLevels(p, opts)
+	}
+	return results, opts.TargetDB, nil
+}
+
+// MatchSetlistFiles matches the volume of .hlx files (or of every .hlx file of the folders given) on the
+// reference file. With write, the files are rewritten in place, otherwise the report only lists the trims.
+// Files that cannot be read are reported and left alone.
+func (db *CatalogDB) MatchSetlistFiles(paths []string, reference string, opts GainStaging, write bool) (SetlistReport, error) {
+	report := SetlistReport{Reference: filepath.Base(reference), Presets: []SetlistEntry{}}
+
+	files, err := expandHLXPaths(paths)
+	if err != nil {
+		return report, err
+	}
+	refAbs, _ := filepath.Abs(reference)
+
+	var presets []*Preset
+	var loaded []int         // Index in report.Presets of every loaded preset
+	var loadedFiles []string // Path of every loaded preset
+	refIndex := -1
+	for _, f := range files {
+		entry := SetlistEntry{File: filepath.Base(f)}
+		p, err := readPresetFile(f)
+		if err != nil {
+			entry.Error = err.Error()
+			report.Presets = append(report.Presets, entry)
+			continue
+		}
+		if abs, _ := filepath.Abs(f); abs == refAbs {
+			refIndex = len(presets)
+		}
+		entry.Name, _ = nestedMap(map[string]interface{}(*p), "data", "meta")["name"].(string)
+		entry.EstimatedDB = db.PresetLevel(p)
+		presets = append(presets, p)
+		loaded = append(loaded, len(report.Presets))
+		loadedFiles = append(loadedFiles, f)
+		report.Presets = append(report.Presets, entry)
+	}
+	if refIndex < 0 {
+		return report, fmt.Errorf("reference preset %s is not part of the setlist", report.Reference)
+	}
+
+	results, refDB, err := db.MatchLevels(presets, refIndex, opts)
+	if err != nil {
+		return report, err
+	}
+	report.ReferenceDB = refDB
+
+	for i, result := range results {
+		if i == refIndex {
+			continue
+		}
+		entry := &report.Presets[loaded[i]]
+		if len(result.Snapshots) > 0 {
+			sum := 0.0
+			for _, lvl := range result.Snapshots {
+				sum += lvl.TrimDB
+			}
+			entry.TrimDB = round1(sum / float64(len(result.Snapshots)))
+		}
+		if !write {
+			continue
+		}
+		if err := writePresetFile(loadedFiles[i], result.Preset); err != nil {
+			entry.Error = err.Error()
+		}
+	}
+	return report, nil
+}
+
+// expandHLXPaths replaces folders by the .hlx files they contain, sorted by name
+func expandHLXPaths(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(path, "*.hlx"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func readPresetFile(path string) (*Preset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Preset
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("not a valid .hlx file: %v", err)
+	}
+	if _, ok := p.tone(); !ok {
+		return nil, fmt.Errorf("not a valid .hlx file: no tone section")
+	}
+	return &p, nil
+}
+
+func writePresetFile(path string, p *Preset) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
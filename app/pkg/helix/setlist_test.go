@@ -0,0 +1,78 @@
+package helix
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newAmpPreset builds a single amp enabled in every snapshot, at the given channel volume
+func newAmpPreset(t *testing.T, chVol float64) *Preset {
+	t.Helper()
+	b := newTestBuilder(t, true)
+	amp, err := b.AddBlock(BlockPlan{Name: "Amp", ModelName: "Brit Plexi Brt", Params: map[string]interface{}{
+		"Drive": 0.5, "Master": 0.5, "ChVol": chVol,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s := 0; s < SnapshotCount; s++ {
+		b.SetSnapshotBypass(amp, s, true)
+	}
+	return b.Preset
+}
+
+func TestMatchSetlistFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, chVol := range map[string]float64{"clean.hlx": 0.3, "crunch.hlx": 0.6, "lead.hlx": 1.0} {
+		if err := writePresetFile(filepath.Join(dir, name), newAmpPreset(t, chVol)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.hlx"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reference := filepath.Join(dir, "clean.hlx")
+
+	t.Run("Dry Run", func(t *testing.T) {
+		report, err := DB.MatchSetlistFiles([]string{dir}, reference, DefaultGainStaging, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Presets) != 4 || report.Presets[0].File != "broken.hlx" || report.Presets[0].Error == "" {
+			t.Fatalf("report = %+v", report)
+		}
+		p, _ := readPresetFile(filepath.Join(dir, "lead.hlx"))
+		if level := DB.PresetLevel(p); level == report.ReferenceDB {
+			t.Errorf("lead.hlx was rewritten by a dry run")
+		}
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		report, err := DB.MatchSetlistFiles([]string{dir}, reference, DefaultGainStaging, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"crunch.hlx", "lead.hlx"} {
+			p, err := readPresetFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if level := DB.PresetLevel(p); math.Abs(level-report.ReferenceDB) > DefaultGainStaging.ToleranceDB {
+				t.Errorf("%s at %.1f dB, reference at %.1f dB", name, level, report.ReferenceDB)
+			}
+		}
+		for _, entry := range report.Presets {
+			if entry.File == "clean.hlx" && entry.TrimDB != 0 {
+				t.Errorf("the reference was trimmed by %.1f dB", entry.TrimDB)
+			}
+		}
+	})
+
+	t.Run("Reference Outside The Setlist", func(t *testing.T) {
+		if _, err := DB.MatchSetlistFiles([]string{filepath.Join(dir, "lead.hlx")}, reference, DefaultGainStaging, false); err == nil {
+			t.Error("MatchSetlistFiles() succeeded without the reference preset")
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package helix
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNoteSeconds(t *testing.T) {
+	tests := []struct {
+		note string
+		bpm  float64
+		want float64
+		ok   bool
+	}{
+		{"1/4", 120, 0.5, true},
+		{"1/8.", 120, 0.375, true},
+		{"dotted eighth", 100, 0.45, true},
+		{"1/4T", 90, 0.4444, true},
+		{"1/1", 60, 4, true},
+		{"1/5", 120, 0, false},
+		{"1/4", 0, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := NoteSeconds(tt.note, tt.bpm)
+		if ok != tt.ok || math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("NoteSeconds(%q, %v) = %v, %v, want %v, %v", tt.note, tt.bpm, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBuilderTempo(t *testing.T) {
+	b := newTestBuilder(t, true)
+	b.SetTempo(100)
+	b.SetSnapshotTempo(1, 300)
+	tone, _ := b.Preset.tone()
+
+	if got := b.Tempo(); got != 100 {
+		t.Errorf("Tempo() = %v, want 100", got)
+	}
+	if got := nestedMap(tone, "snapshot0")["@tempo"]; got != 100.0 {
+		t.Errorf("snapshot0 @tempo = %v, want 100", got)
+	}
+	if got := nestedMap(tone, "snapshot1")["@tempo"]; got != MaxTempo {
+		t.Errorf("snapshot1 @tempo = %v, want %v", got, MaxTempo)
+	}
+
+	tests := []struct {
+		name       string
+		model      string
+		note       string
+		wantSynced bool
+	}{
+		{"Delay", "Simple Delay", "dotted eighth", true},
+		{"Modulation", "HD2_Chorus", "1/4", true},
+		{"No Tempo Sync", "Scream 808", "1/4", false},
+		{"Unknown Note", "Simple Delay", "1/5", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block, err := b.AddBlock(BlockPlan{Name: tt.name, ModelName: tt.model})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.SyncToTempo(block, tt.note); got != tt.wantSynced {
+				t.Fatalf("SyncToTempo() = %v, want %v", got, tt.wantSynced)
+			}
+			if !tt.wantSynced {
+				return
+			}
+			if v, _ := b.Value(block, "TempoSync1"); v != true {
+				t.Errorf("TempoSync1 = %v, want true", v)
+			}
+			if v, _ := b.Value(block, "SyncSelect1"); v != float64(NoteIndex(tt.note)) {
+				t.Errorf("SyncSelect1 = %v, want %d", v, NoteIndex(tt.note))
+			}
+		})
+	}
+
+	t.Run("Time Follows The Tempo", func(t *testing.T) {
+		block, _ := b.AddBlock(BlockPlan{Name: "Delay 2", ModelName: "Simple Delay", Path: 1})
+		b.SyncToTempo(block, "1/8.")
+		if v, _ := b.Value(block, "Time"); v != 0.45 {
+			t.Errorf("Time = %v, want 0.45", v)
+		}
+	})
+}
@@ -0,0 +1,330 @@
+package helix
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Diagnostic severities
+const (
+	SeverityError   = "error"   // HX Edit refuses the preset, or it misbehaves on the hardware
+	SeverityWarning = "warning" // Loads, but probably not what was intended
+	SeverityInfo    = "info"
+)
+
+// Diagnostic codes
+const (
+	DiagInvalidSchema          = "invalid_schema"
+	DiagNameTooLong            = "name_too_long"
+	DiagMissingModel           = "missing_model"
+	DiagUnknownModel           = "unknown_model"
+	DiagPathUnavailable        = "path_unavailable"
+	DiagInvalidPosition        = "invalid_position"
+	DiagTooManyBlocks          = "too_many_blocks"
+	DiagDuplicatePosition      = "duplicate_position"
+	DiagSnapshotUnknownBlock   = "snapshot_unknown_block"
+	DiagSnapshotMissingBlock   = "snapshot_missing_block"
+	DiagControllerUnknownBlock = "controller_unknown_block"
+	DiagControllerUnknownParam = "controller_unknown_param"
+	DiagControllerUnavailable  = "controller_unavailable"
+	DiagFootswitchUnknownBlock = "footswitch_unknown_block"
+	DiagMIDIInvalid            = "midi_invalid"
+	DiagDSPOverload            = "dsp_overload"
+	DiagDSPHigh                = "dsp_high"
+)
+
+// Limits checked by Validate on every hardware
+const (
+	MaxPresetNameLength = 16   // Longer names are truncated by the hardware
+	DSPWarningThreshold = 85.0 // % of the DSP capacity
+)
+
+// HardwareLimits are the block and DSP limits of a path of a hardware target
+type HardwareLimits struct {
+	Positions   int     // Positions of each sub-path
+	SubPaths    int     // 2 when the path can split into A/B
+	MaxBlocks   int     // Blocks of the path, both sub-paths together
+	DSPCapacity float64 // In the unit of the catalog's DSP costs (% of a Helix DSP chip); 0 = not comparable, unchecked
+}
+
+// LimitsFor returns the limits of each path of the hardware, from the Line 6 owner's manuals
+func LimitsFor(hardware string) HardwareLimits {
+	switch {
+	case IsDualDSP(hardware):
+		return HardwareLimits{Positions: 8, SubPaths: 2, MaxBlocks: 16, DSPCapacity: 100}
+	case strings.Contains(strings.ToLower(hardware), "pod go"):
+		// Single path of fixed and user blocks. Its DSP is not the Helix chip the catalog costs are measured on.
+		return HardwareLimits{Positions: 10, SubPaths: 1, MaxBlocks: 10}
+	case strings.Contains(hardware, "Effects"):
+		return HardwareLimits{Positions: 9, SubPaths: 2, MaxBlocks: 9, DSPCapacity: 100}
+	}
+	// HX Stomp and HX Stomp XL (firmware 3.0 and later): one Helix DSP chip
+	return HardwareLimits{Positions: 8, SubPaths: 2, MaxBlocks: 8, DSPCapacity: 100}
+}
+
+// Diagnostic is one problem found in a preset
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Location string `json:"location"` // Where in data.tone, e.g. "dsp0.block3" or "snapshot2.blocks.dsp0.block5"
+	Message  string `json:"message"`
+	Fix      string `json:"fix,omitempty"` // Suggested action
+}
+
+// Validate checks a preset against the latest catalog (with the learned models) for the given hardware
+func Validate(p *Preset, hardware string) []Diagnostic {
+	return DB.Validate(p, hardware)
+}
+
+// Validate checks the shape of the preset, its models, block positions, snapshot, controller and
+// footswitch references and DSP totals. Diagnostics are sorted by severity then location.
+func (db *CatalogDB) Validate(p *Preset, hardware string) []Diagnostic {
+	db.EnsureLoaded()
+	v := &validator{db: db, dualDSP: IsDualDSP(hardware), limits: LimitsFor(hardware), pedals: ExpressionPedals(hardware), diags: []Diagnostic{}}
+	v.run(p)
+
+	rank := map[string]int{SeverityError: 0, SeverityWarning: 1, SeverityInfo: 2}
+	sort.SliceStable(v.diags, func(i, j int) bool {
+		if rank[v.diags[i].Severity] != rank[v.diags[j].Severity] {
+			return rank[v.diags[i].Severity] < rank[v.diags[j].Severity]
+		}
+		return v.diags[i].Location < v.diags[j].Location
+	})
+	return v.diags
+}
+
+// HasErrors reports whether some diagnostic is an error
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+type validator struct {
+	db      *CatalogDB
+	dualDSP bool
+	limits  HardwareLimits
+	pedals  int // Expression pedals of the hardware
+	diags   []Diagnostic
+	blocks  map[string]map[string]interface{} // "dsp0.block3" -> block
+	fixed   map[string]map[string]interface{} // "dsp0.outputA" -> input, output, split and join blocks
+}
+
+func (v *validator) add(severity, code, location, message, fix string) {
+	v.diags = append(v.diags, Diagnostic{Severity: severity, Code: code, Location: location, Message: message, Fix: fix})
+}
+
+func (v *validator) run(p *Preset) {
+	data, ok := (*p)["data"].(map[string]interface{})
+	if !ok {
+		v.add(SeverityError, DiagInvalidSchema, "data", "the preset has no data section", "")
+		return
+	}
+	if name, ok := nestedMap(data, "meta")["name"].(string); !ok {
+		v.add(SeverityError, DiagInvalidSchema, "meta.name", "the preset has no name", "")
+	} else if len([]rune(name)) > MaxPresetNameLength {
+		v.add(SeverityWarning, DiagNameTooLong, "meta.name", fmt.Sprintf("%q is longer than %d characters", name, MaxPresetNameLength), "shorten the name, the hardware truncates it")
+	}
+	tone, ok := data["tone"].(map[string]interface{})
+	if !ok {
+		v.add(SeverityError, DiagInvalidSchema, "tone", "the preset has no tone section", "")
+		return
+	}
+	if _, ok := tone["dsp0"].(map[string]interface{}); !ok {
+		v.add(SeverityError, DiagInvalidSchema, "dsp0", "the preset has no dsp0 section", "")
+	}
+	for s := 0; s < SnapshotCount; s++ {
+		if _, ok := tone[fmt.Sprintf("snapshot%d", s)].(map[string]interface{}); !ok {
+			v.add(SeverityError, DiagInvalidSchema, fmt.Sprintf("snapshot%d", s), "the snapshot is missing", "")
+		}
+	}
+
+	v.checkBlocks(tone)
+	v.checkSnapshots(tone)
+	v.checkControllers(tone)
+}
+
+// checkBlocks checks models, positions and DSP usage of every block
+func (v *validator) checkBlocks(tone map[string]interface{}) {
+	v.blocks = make(map[string]map[string]interface{})
+	v.fixed = make(map[string]map[string]interface{})
+	for path := 0; path < 2; path++ {
+		dspKey := fmt.Sprintf("dsp%d", path)
+		dsp, _ := tone[dspKey].(map[string]interface{})
+
+		taken := make(map[string]string) // "@path/@position" -> block key
+		usage := 0.0
+		count := 0
+		for _, key := range sortedKeys(dsp) {
+			block, ok := dsp[key].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if !(strings.HasPrefix(key, "block") || strings.HasPrefix(key, "cab")) {
+				v.fixed[dspKey+"."+key] = block
+				continue
+			}
+			loc := dspKey + "." + key
+			v.blocks[loc] = block
+
+			if path == 1 && !v.dualDSP {
+				v.add(SeverityError, DiagPathUnavailable, loc, "the hardware has a single DSP but the block is on Path 2", "move the block to Path 1")
+			}
+
+			model, _ := block["@model"].(string)
+			if model == "" {
+				v.add(SeverityError, DiagMissingModel, loc, "the block has no @model", "")
+			} else if _, ok := v.db.FindByID(model); !ok {
+				fix := "delete the block or pick another model"
+				if original, ok := DB.FindByID(model); ok {
+					if sub, ok := v.db.Substitute(original); ok {
+						fix = fmt.Sprintf("replace it with %s", sub.Name)
+					}
+				}
+				v.add(SeverityError, DiagUnknownModel, loc, fmt.Sprintf("model %s does not exist on firmware %s", model, v.db.Firmware), fix)
+			}
+			usage += v.db.DSPCost(model)
+
+			if !strings.HasPrefix(key, "block") {
+				continue
+			}
+			count++
+			pos, posOK := number(block["@position"])
+			sub, subOK := number(block["@path"])
+			if !posOK || !subOK || pos < 0 || pos >= v.limits.Positions || sub < 0 || sub >= v.limits.SubPaths {
+				v.add(SeverityError, DiagInvalidPosition, loc, fmt.Sprintf("invalid position %v on sub-path %v", block["@position"], block["@path"]), fmt.Sprintf("use a @position from 0 to %d and a @path from 0 to %d", v.limits.Positions-1, v.limits.SubPaths-1))
+				continue
+			}
+			slot := fmt.Sprintf("%v/%v", sub, pos)
+			if other, ok := taken[slot]; ok {
+				v.add(SeverityError, DiagDuplicatePosition, loc, fmt.Sprintf("same position as %s.%s", dspKey, other), "move one of the blocks")
+			}
+			taken[slot] = key
+		}
+
+		fix := "remove a block"
+		if v.dualDSP {
+			fix = "remove a block or move blocks to the other path"
+		}
+		if count > v.limits.MaxBlocks {
+			v.add(SeverityError, DiagTooManyBlocks, dspKey, fmt.Sprintf("Path %d has %d blocks, the hardware allows %d", path+1, count, v.limits.MaxBlocks), fix)
+		}
+		capacity := v.limits.DSPCapacity
+		switch {
+		case capacity == 0:
+			// No DSP costs for this hardware
+		case usage > capacity:
+			v.add(SeverityError, DiagDSPOverload, dspKey, fmt.Sprintf("Path %d needs about %.0f%% DSP", path+1, usage*100/capacity), fix)
+		case usage > capacity*DSPWarningThreshold/100:
+			v.add(SeverityWarning, DiagDSPHigh, dspKey, fmt.Sprintf("Path %d needs about %.0f%% DSP, close to the limit", path+1, usage*100/capacity), "")
+		}
+	}
+}
+
+// checkSnapshots checks that snapshot states and values point at existing blocks, and that every block has a state
+func (v *validator) checkSnapshots(tone map[string]interface{}) {
+	for s := 0; s < SnapshotCount; s++ {
+		snapKey := fmt.Sprintf("snapshot%d", s)
+		snap, ok := tone[snapKey].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for path := 0; path < 2; path++ {
+			dspKey := fmt.Sprintf("dsp%d", path)
+			states := nestedMap(snap, "blocks", dspKey)
+			for _, key := range sortedKeys(states) {
+				if _, ok := v.blocks[dspKey+"."+key]; !ok {
+					v.add(SeverityError, DiagSnapshotUnknownBlock, snapKey+".blocks."+dspKey+"."+key, "the snapshot sets the state of a block that does not exist", "remove the entry")
+				}
+			}
+			values := nestedMap(snap, "controllers", dspKey)
+			for _, key := range sortedKeys(values) {
+				v.checkParams(values[key], dspKey, key, snapKey+".controllers."+dspKey+"."+key)
+			}
+		}
+	}
+
+	for _, loc := range sortedKeys(v.blocks) {
+		if !strings.Contains(loc, ".block") {
+			continue
+		}
+		dspKey, key, _ := strings.Cut(loc, ".")
+		for s := 0; s < SnapshotCount; s++ {
+			snap, ok := tone[fmt.Sprintf("snapshot%d", s)].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, ok := nestedMap(snap, "blocks", dspKey)[key].(bool); !ok {
+				v.add(SeverityWarning, DiagSnapshotMissingBlock, fmt.Sprintf("snapshot%d.blocks.%s", s, loc), "the block has no state in this snapshot", "set the block on or off in the snapshot")
+			}
+		}
+	}
+}
+
+// checkControllers checks that controller and footswitch assignments point at existing blocks and parameters
+func (v *validator) checkControllers(tone map[string]interface{}) {
+	for path := 0; path < 2; path++ {
+		dspKey := fmt.Sprintf("dsp%d", path)
+		ctrls := nestedMap(tone, "controller", dspKey)
+		for _, key := range sortedKeys(ctrls) {
+			v.checkParams(ctrls[key], dspKey, key, "controller."+dspKey+"."+key)
+		}
+		footswitches := nestedMap(tone, "footswitch", dspKey)
+		for _, key := range sortedKeys(footswitches) {
+			if _, ok := v.blocks[dspKey+"."+key]; !ok {
+				v.add(SeverityError, DiagFootswitchUnknownBlock, "footswitch."+dspKey+"."+key, "a footswitch targets a block that does not exist", "remove the assignment")
+			}
+		}
+	}
+}
+
+// checkParams checks a controller entry: its block must exist and have the assigned parameters.
+// Input and output blocks (inputA, outputA, split, join) can also be controlled.
+func (v *validator) checkParams(raw interface{}, dspKey, key, loc string) {
+	block, ok := v.blocks[dspKey+"."+key]
+	if !ok && !(strings.HasPrefix(key, "block") || strings.HasPrefix(key, "cab")) {
+		block, ok = v.fixed[dspKey+"."+key]
+	}
+	if !ok {
+		v.add(SeverityError, DiagControllerUnknownBlock, loc, "a controller targets a block that does not exist", "remove the assignment")
+		return
+	}
+	params, _ := raw.(map[string]interface{})
+	for _, param := range sortedKeys(params) {
+		if _, ok := block[param]; !ok {
+			v.add(SeverityWarning, DiagControllerUnknownParam, loc+"."+param, fmt.Sprintf("the block has no parameter %q", param), "remove the assignment")
+		}
+		ctrl, _ := params[param].(map[string]interface{})
+		id, _ := number(ctrl["@controller"])
+		if id >= ControllerExp1 && id <= ControllerExp3 && id > v.pedals {
+			v.add(SeverityError, DiagControllerUnavailable, loc+"."+param, fmt.Sprintf("the hardware has no EXP %d pedal", id), fmt.Sprintf("assign the parameter to EXP 1 to %d", v.pedals))
+		}
+		if cc, ok := number(ctrl["@cc"]); id == ControllerMIDICC && (!ok || cc < 0 || cc > 127 || reservedCC[cc]) {
+			v.add(SeverityError, DiagMIDIInvalid, loc+"."+param, fmt.Sprintf("invalid or reserved MIDI CC %v", ctrl["@cc"]), "use a CC Helix does not reserve")
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// number reads an integral JSON number (int when built, float64 when decoded)
+func number(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), n == float64(int(n))
+	}
+	return 0, false
+}
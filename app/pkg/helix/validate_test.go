@@ -0,0 +1,132 @@
+package helix
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newValidPreset builds Scream 808 > Brit Plexi Brt on Path 1, enabled in every snapshot
+func newValidPreset(t *testing.T) *Preset {
+	t.Helper()
+	b := newTestBuilder(t, true)
+	for _, plan := range []BlockPlan{{Name: "Drive", ModelName: "Scream 808"}, {Name: "Amp", ModelName: "Brit Plexi Brt"}} {
+		block, err := b.AddBlock(plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for s := 0; s < SnapshotCount; s++ {
+			b.SetSnapshotBypass(block, s, true)
+		}
+	}
+	return b.Preset
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		hardware string
+		mutate   func(tone map[string]interface{})
+		wantCode string
+		wantSev  string
+	}{
+		{"Valid", "Helix Floor", func(tone map[string]interface{}) {}, "", ""},
+		{"Unknown Model", "Helix Floor", func(tone map[string]interface{}) {
+			nestedMap(tone, "dsp0", "block0")["@model"] = "HD2_DistDoesNotExist"
+		}, DiagUnknownModel, SeverityError},
+		{"Duplicate Position", "Helix Floor", func(tone map[string]interface{}) {
+			nestedMap(tone, "dsp0", "block1")["@position"] = 0.0
+		}, DiagDuplicatePosition, SeverityError},
+		{"Position Out Of Range", "Helix Floor", func(tone map[string]interface{}) {
+			nestedMap(tone, "dsp0", "block1")["@position"] = 8
+		}, DiagInvalidPosition, SeverityError},
+		{"Snapshot References A Missing Block", "Helix Floor", func(tone map[string]interface{}) {
+			nestedMap(tone, "snapshot3", "blocks", "dsp0")["block5"] = true
+		}, DiagSnapshotUnknownBlock, SeverityError},
+		{"Block Without Snapshot State", "Helix Floor", func(tone map[string]interface{}) {
+			delete(nestedMap(tone, "snapshot2", "blocks", "dsp0"), "block1")
+		}, DiagSnapshotMissingBlock, SeverityWarning},
+		{"Controller References A Missing Block", "Helix Floor", func(tone map[string]interface{}) {
+			nestedMap(tone, "controller", "dsp0")["block4"] = map[string]interface{}{"Gain": map[string]interface{}{"@controller": ControllerSnapshot}}
+		}, DiagControllerUnknownBlock, SeverityError},
+		{"Controller References A Missing Param", "Helix Floor", func(tone map[string]interface{}) {
+			nestedMap(tone, "controller", "dsp0")["block0"] = map[string]interface{}{"Mix": map[string]interface{}{"@controller": ControllerSnapshot}}
+		}, DiagControllerUnknownParam, SeverityWarning},
+		{"Missing Expression Pedal", "HX Stomp", func(tone map[string]interface{}) {
+			nestedMap(tone, "controller", "dsp0")["block0"] = map[string]interface{}{"Gain": map[string]interface{}{"@controller": ControllerExp3}}
+		}, DiagControllerUnavailable, SeverityError},
+		{"Reserved MIDI CC", "Helix Floor", func(tone map[string]interface{}) {
+			nestedMap(tone, "controller", "dsp0")["block0"] = map[string]interface{}{"Gain": map[string]interface{}{"@controller": ControllerMIDICC, "@cc": 64}}
+		}, DiagMIDIInvalid, SeverityError},
+		{"Footswitch References A Missing Block", "Helix Floor", func(tone map[string]interface{}) {
+			nestedMap(tone, "footswitch", "dsp0")["block6"] = map[string]interface{}{"@fs_index": 1}
+		}, DiagFootswitchUnknownBlock, SeverityError},
+		{"DSP Overload", "Helix Floor", func(tone map[string]interface{}) {
+			dsp := nestedMap(tone, "dsp0")
+			for i := 2; i < 5; i++ {
+				dsp[fmt.Sprintf("block%d", i)] = map[string]interface{}{"@model": "HD2_AmpBritPlexiBrt", "@position": i, "@path": 0}
+			}
+		}, DiagDSPOverload, SeverityError},
+		{"Path 2 On Single DSP", "HX Stomp", func(tone map[string]interface{}) {
+			nestedMap(tone, "dsp1")["block0"] = map[string]interface{}{"@model": "HD2_DistScream808", "@position": 0, "@path": 0}
+		}, DiagPathUnavailable, SeverityError},
+		{"Sub-Path B On POD Go", "POD Go", func(tone map[string]interface{}) {
+			nestedMap(tone, "dsp0", "block1")["@path"] = 1
+		}, DiagInvalidPosition, SeverityError},
+		{"Missing Snapshot", "Helix Floor", func(tone map[string]interface{}) {
+			delete(tone, "snapshot7")
+		}, DiagInvalidSchema, SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newValidPreset(t)
+			tone, _ := p.tone()
+			tt.mutate(tone)
+
+			diags := Validate(p, tt.hardware)
+			if tt.wantCode == "" {
+				if len(diags) != 0 {
+					t.Errorf("Validate() = %+v, want no diagnostics", diags)
+				}
+				return
+			}
+			for _, d := range diags {
+				if d.Code == tt.wantCode {
+					if d.Severity != tt.wantSev {
+						t.Errorf("%s severity = %s, want %s", d.Code, d.Severity, tt.wantSev)
+					}
+					if HasErrors(diags) != (tt.wantSev == SeverityError) {
+						t.Errorf("HasErrors() = %v", HasErrors(diags))
+					}
+					return
+				}
+			}
+			t.Errorf("Validate() = %+v, want a %s diagnostic", diags, tt.wantCode)
+		})
+	}
+
+	t.Run("Limits Per Hardware", func(t *testing.T) {
+		// 9 blocks on Path 1: too many for an HX Stomp, not for a Helix
+		p := newValidPreset(t)
+		tone, _ := p.tone()
+		for i := 2; i < 9; i++ {
+			nestedMap(tone, "dsp0")[fmt.Sprintf("block%d", i)] = map[string]interface{}{"@model": "HD2_DistScream808", "@position": i % 8, "@path": i / 8}
+		}
+		for hardware, want := range map[string]bool{"HX Stomp": true, "HX Stomp XL": true, "HX Effects": false, "Helix Floor": false} {
+			got := false
+			for _, d := range Validate(p, hardware) {
+				got = got || d.Code == DiagTooManyBlocks
+			}
+			if got != want {
+				t.Errorf("%s: too many blocks = %v, want %v", hardware, got, want)
+			}
+		}
+	})
+
+	t.Run("Missing Data", func(t *testing.T) {
+		diags := Validate(&Preset{}, "Helix Floor")
+		if len(diags) != 1 || diags[0].Code != DiagInvalidSchema {
+			t.Errorf("Validate() = %+v", diags)
+		}
+	})
+}
@@ -0,0 +1,264 @@
+package usage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is the token usage of one provider call
+type Record struct {
+	Time           time.Time `json:"time"`
+	ChatID         string    `json:"chat_id,omitempty"`
+	Stage          string    `json:"stage"`
+	Model          string    `json:"model"`
+	PromptTokens   int       `json:"prompt_tokens"`
+	ResponseTokens int       `json:"response_tokens"`
+}
+
+// Price is the cost of a model in USD per million tokens
+type Price struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// DefaultPrices are the public Gemini API list prices (paid tier, prompts up to 200k tokens).
+// Models are matched by longest prefix; user prices from the settings override these.
+var DefaultPrices = map[string]Price{
+	"gemini-2.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+	"gemini-2.5-flash":      {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+	"gemini-2.5-flash-lite": {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	"gemini-2.0-flash":      {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	"gemini-2.0-flash-lite": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+}
+
+// Totals aggregates a set of records
+type Totals struct {
+	Requests       int     `json:"requests"`
+	PromptTokens   int     `json:"prompt_tokens"`
+	ResponseTokens int     `json:"response_tokens"`
+	Cost           float64 `json:"cost"`   // USD
+	Priced         bool    `json:"priced"` // False if some records used a model without a known price
+}
+
+// Summary is the usage aggregated per chat, per day (local time, "2006-01-02") and per model
+type Summary struct {
+	Total   Totals            `json:"total"`
+	ByChat  map[string]Totals `json:"by_chat"`
+	ByDay   map[string]Totals `json:"by_day"`
+	ByModel map[string]Totals `json:"by_model"`
+	Days    []string          `json:"days"` // Keys of ByDay, most recent first
+}
+
+// Ledger is the local, append-only usage history, stored as one JSON record per line (.jsonl)
+type Ledger struct {
+	mu         sync.Mutex
+	path       string
+	legacyPath string // JSON array file of older versions, imported while path does not exist
+	records    []Record
+	loaded     bool
+	legacy     bool // The records come from a JSON array; the next Add rewrites them as lines
+}
+
+// NewLedger returns a ledger backed by the given file (created on first Add).
+// legacyPath is the JSON array file written by older versions ("" = none): while path does not exist,
+// its records are read, then moved to path by the next Add.
+func NewLedger(path, legacyPath string) *Ledger {
+	return &Ledger{path: path, legacyPath: legacyPath}
+}
+
+// load reads the file once. A missing file starts an empty ledger; unreadable records are skipped and reported.
+func (l *Ledger) load() error {
+	if l.loaded {
+		return nil
+	}
+	l.loaded = true
+	data, err := os.ReadFile(l.path)
+	if os.IsNotExist(err) && l.legacyPath != "" {
+		data, err = os.ReadFile(l.legacyPath)
+		l.legacy = err == nil
+	}
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading usage ledger: %v", err)
+	}
+	var legacy bool
+	l.records, legacy, err = parseRecords(data)
+	l.legacy = l.legacy || legacy
+	return err
+}
+
+// parseRecords reads JSON lines, or the JSON array of older versions
+func parseRecords(data []byte) ([]Record, bool, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var records []Record
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, true, fmt.Errorf("usage ledger is corrupt, starting a new one: %v", err)
+		}
+		return records, true, nil
+	}
+
+	var records []Record
+	skipped := 0
+	for _, line := range bytes.Split(trimmed, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(line, &r); err != nil {
+			skipped++
+			continue
+		}
+		records = append(records, r)
+	}
+	if skipped > 0 {
+		return records, false, fmt.Errorf("usage ledger: %d unreadable records skipped", skipped)
+	}
+	return records, false, nil
+}
+
+// Add appends a record to the ledger file. A load error is returned after the record is saved.
+func (l *Ledger) Add(r Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	loadErr := l.load()
+
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	l.records = append(l.records, r)
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	if l.legacy {
+		if err := l.rewrite(); err != nil {
+			return err
+		}
+		l.legacy = false
+		if l.legacyPath != "" && l.legacyPath != l.path {
+			if err := os.Remove(l.legacyPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return loadErr
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return loadErr
+}
+
+// rewrite replaces the file with all the records, one per line
+func (l *Ledger) rewrite() error {
+	var buf bytes.Buffer
+	for _, r := range l.records {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// Summary aggregates the ledger. prices override DefaultPrices (nil uses the defaults only).
+// The summary covers the readable records even when an error is returned.
+func (l *Ledger) Summary(prices map[string]Price) (Summary, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := l.load()
+	return Summarize(l.records, prices), err
+}
+
+// Summarize aggregates records per chat, day and model
+func Summarize(records []Record, prices map[string]Price) Summary {
+	s := Summary{
+		Total:   Totals{Priced: true},
+		ByChat:  make(map[string]Totals),
+		ByDay:   make(map[string]Totals),
+		ByModel: make(map[string]Totals),
+		Days:    []string{},
+	}
+	for _, r := range records {
+		cost, priced := Cost(r, prices)
+		add := func(t Totals) Totals {
+			if t.Requests == 0 {
+				t.Priced = true
+			}
+			t.Requests++
+			t.PromptTokens += r.PromptTokens
+			t.ResponseTokens += r.ResponseTokens
+			t.Cost += cost
+			t.Priced = t.Priced && priced
+			return t
+		}
+
+		s.Total = add(s.Total)
+		if r.ChatID != "" {
+			s.ByChat[r.ChatID] = add(s.ByChat[r.ChatID])
+		}
+		day := r.Time.Local().Format("2006-01-02")
+		s.ByDay[day] = add(s.ByDay[day])
+		s.ByModel[r.Model] = add(s.ByModel[r.Model])
+	}
+
+	for day := range s.ByDay {
+		s.Days = append(s.Days, day)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(s.Days)))
+	return s
+}
+
+// Cost returns the USD cost of a record, and false if its model has no known price
+func Cost(r Record, prices map[string]Price) (float64, bool) {
+	p, ok := PriceFor(r.Model, prices)
+	if !ok {
+		return 0, false
+	}
+	return float64(r.PromptTokens)/1e6*p.InputPerMillion + float64(r.ResponseTokens)/1e6*p.OutputPerMillion, true
+}
+
+// PriceFor finds the price of a model: user prices first, then DefaultPrices, matching the longest prefix
+func PriceFor(model string, prices map[string]Price) (Price, bool) {
+	model = strings.TrimPrefix(model, "models/")
+	for _, table := range []map[string]Price{prices, DefaultPrices} {
+		best := ""
+		for prefix := range table {
+			if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+				best = prefix
+			}
+		}
+		if best != "" {
+			return table[best], true
+		}
+	}
+	return Price{}, false
+}
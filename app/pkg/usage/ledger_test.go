@@ -0,0 +1,96 @@
+package usage
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	day1 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+
+	l := NewLedger(path, "")
+	records := []Record{
+		{Time: day1, ChatID: "a", Stage: "sound_engineer", Model: "gemini-2.5-flash", PromptTokens: 1_000_000, ResponseTokens: 100_000},
+		{Time: day1, ChatID: "a", Stage: "preset_engineer", Model: "models/gemini-2.5-flash-lite", PromptTokens: 500_000},
+		{Time: day2, ChatID: "b", Stage: "sound_engineer", Model: "my-local-model", PromptTokens: 10, ResponseTokens: 10},
+	}
+	for _, r := range records {
+		if err := l.Add(r); err != nil {
+			t.Fatalf("Add() error: %v", err)
+		}
+	}
+
+	// Reload from disk
+	s, err := NewLedger(path, "").Summary(nil)
+	if err != nil {
+		t.Fatalf("Summary() error: %v", err)
+	}
+	if s.Total.Requests != 3 || s.Total.Priced {
+		t.Errorf("Total = %+v, want 3 requests, not fully priced", s.Total)
+	}
+	// 1M * 0.30 + 0.1M * 2.50 + 0.5M * 0.10 (lite wins over the flash prefix)
+	if chatA := s.ByChat["a"]; math.Abs(chatA.Cost-0.60) > 1e-9 || !chatA.Priced {
+		t.Errorf("ByChat[a] = %+v, want cost 0.60", chatA)
+	}
+	if len(s.Days) != 2 || s.Days[0] != day2.Format("2006-01-02") {
+		t.Errorf("Days = %v, want most recent first", s.Days)
+	}
+
+	t.Run("User Prices Override", func(t *testing.T) {
+		prices := map[string]Price{"my-local": {InputPerMillion: 1e6, OutputPerMillion: 1e6}}
+		s, _ := l.Summary(prices)
+		if b := s.ByChat["b"]; b.Cost != 20 || !b.Priced {
+			t.Errorf("ByChat[b] = %+v, want cost 20", b)
+		}
+	})
+	t.Run("Appends One Line Per Record", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(string(data), "\n"); lines != 3 {
+			t.Errorf("ledger has %d lines, want 3", lines)
+		}
+	})
+
+	t.Run("Legacy Array Is Migrated", func(t *testing.T) {
+		dir := t.TempDir()
+		lines, legacy := filepath.Join(dir, "usage.jsonl"), filepath.Join(dir, "usage.json")
+		old := `[{"time":"2025-03-01T12:00:00Z","stage":"sound_engineer","model":"gemini-2.5-flash","prompt_tokens":10,"response_tokens":0}]`
+		if err := os.WriteFile(legacy, []byte(old), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if s, err := NewLedger(lines, legacy).Summary(nil); err != nil || s.Total.Requests != 1 {
+			t.Errorf("Summary() before migration = %+v, %v, want the legacy record", s.Total, err)
+		}
+		l := NewLedger(lines, legacy)
+		if err := l.Add(Record{Stage: "preset_engineer", Model: "gemini-2.5-flash", PromptTokens: 5}); err != nil {
+			t.Fatalf("Add() error: %v", err)
+		}
+		if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+			t.Errorf("the legacy file should be removed once migrated")
+		}
+		s, err := NewLedger(lines, legacy).Summary(nil)
+		if err != nil || s.Total.Requests != 2 || s.Total.PromptTokens != 15 {
+			t.Errorf("Summary() = %+v, %v, want 2 requests / 15 prompt tokens", s.Total, err)
+		}
+	})
+
+	t.Run("Corrupt Lines Are Reported", func(t *testing.T) {
+		broken := filepath.Join(t.TempDir(), "usage.jsonl")
+		content := `{"stage":"sound_engineer","model":"gemini-2.5-flash","prompt_tokens":10}` + "\n{oops\n"
+		if err := os.WriteFile(broken, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		s, err := NewLedger(broken, "").Summary(nil)
+		if err == nil || s.Total.Requests != 1 {
+			t.Errorf("Summary() = %+v, %v, want 1 request and an error", s.Total, err)
+		}
+	})
+}